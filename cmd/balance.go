@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+var balanceSorts = map[string]func(a, b splitter.Balance) bool{
+	"member": func(a, b splitter.Balance) bool { return a.Membername < b.Membername },
+	"paid":   func(a, b splitter.Balance) bool { return a.Paid > b.Paid },
+	"share":  func(a, b splitter.Balance) bool { return a.Share > b.Share },
+	"net":    func(a, b splitter.Balance) bool { return a.Net > b.Net },
+	"count":  func(a, b splitter.Balance) bool { return a.Transactions > b.Transactions },
+}
+
+func balanceFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "member, m",
+			Value: "",
+			Usage: "Show the balance of the member only (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "sort, s",
+			Value: "net",
+			Usage: "Sort by member, paid, share, net or count. Amounts are sorted biggest first (Optional)",
+		},
+		cli.BoolFlag{
+			Name:  "all-trips, a",
+			Usage: "Add up the balances of every trip",
+		},
+		currencyFlag("Currency to show the balances in, converted at the rates of the transaction dates. Needed when the trips are in different currencies (Optional)"),
+		tripFlag(),
+	}
+}
+
+//BalanceCmd shows what each member paid, shared and owes
+func BalanceCmd() cli.Command {
+	return cli.Command{
+		Name:  "balance",
+		Usage: "Shows the balance of each member",
+		Flags: balanceFlags(),
+		Action: func(c *cli.Context) error {
+			less, ok := balanceSorts[strings.ToLower(c.String("sort"))]
+			if !ok {
+				fmt.Printf("%s  Sort should be one of member, paid, share, net or count\n", devil())
+				return nil
+			}
+
+			trips, err := loadTrips(c)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			members, shares, currency, err := tripShares(c, trips)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			balances := splitter.CreateBalances(0, members, shares)
+			if len(balances) == 0 {
+				fmt.Printf("%s  No transactions found\n", devil())
+				return nil
+			}
+			sort.SliceStable(balances, func(i, j int) bool { return less(balances[i], balances[j]) })
+
+			member := ""
+			if c.String("member") != "" {
+				if member, err = resolveTripsMember(c, trips, c.String("member")); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "MEMBER\tPAID\tSHARE\tSETTLED\tNET\tTRANSACTIONS")
+			found := false
+			for _, balance := range balances {
+				if member != "" && balance.Memberemail != member {
+					continue
+				}
+				found = true
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n", balance.Membername, balance.Paid.Format(currency), balance.Share.Format(currency), balance.Settled.Format(currency), balance.Net.Format(currency), balance.Transactions)
+			}
+			if !found {
+				fmt.Printf("%s  %s has no transactions\n", devil(), member)
+				return nil
+			}
+			w.Flush()
+
+			printMostOwed(balances, currency)
+			return nil
+		},
+	}
+}
+
+// printMostOwed tells who the group has to pay back first and who owes the most
+func printMostOwed(balances []splitter.Balance, currency string) {
+	var owed, owes *splitter.Balance
+	for i := range balances {
+		balance := &balances[i]
+		if balance.Net > 0 && (owed == nil || balance.Net > owed.Net) {
+			owed = balance
+		}
+		if balance.Net < 0 && (owes == nil || balance.Net < owes.Net) {
+			owes = balance
+		}
+	}
+	switch {
+	case owed == nil && owes == nil:
+		fmt.Printf("%s  Everyone is settled\n", celebrate())
+	case owed == nil: // nobody paid yet
+		fmt.Printf("%s  Nobody is owed anything yet, %s owes the most, %s\n", devil(), owes.Membername, (-owes.Net).Format(currency))
+	case owes == nil: // only left by the rounding of the converted amounts
+		fmt.Printf("%s  %s is owed the most, %s, nobody owes anything\n", celebrate(), owed.Membername, owed.Net.Format(currency))
+	default:
+		fmt.Printf("%s  %s is owed the most, %s\n", celebrate(), owed.Membername, owed.Net.Format(currency))
+		fmt.Printf("%s  %s owes the most, %s\n", devil(), owes.Membername, (-owes.Net).Format(currency))
+	}
+}
+
+// resolveTripsMember resolves the name or alias of the member in the first of the trips knowing it
+func resolveTripsMember(c *cli.Context, trips []*database.Trip, name string) (string, error) {
+	var err error
+	for _, trip := range trips {
+		names, namesErr := tripMemberNames(c, trip.Name)
+		if namesErr != nil {
+			return "", namesErr
+		}
+		var member string
+		if member, err = names.resolve(name); err == nil {
+			return member, nil
+		}
+	}
+	if err == nil { // no trips
+		err = fmt.Errorf("%s is not a member of the trip", database.NormaliseName(name))
+	}
+	return "", err
+}
+
+// loadTrips loads the trip given with --trip or the current trip, or every trip with --all-trips
+func loadTrips(c *cli.Context) ([]*database.Trip, error) {
+	if !c.Bool("all-trips") {
+		trip, err := loadTrip(c)
+		if err != nil {
+			return nil, err
+		}
+		return []*database.Trip{trip}, nil
+	}
+
+	infos, err := database.Trips()
+	if err != nil {
+		return nil, err
+	}
+	trips := make([]*database.Trip, 0, len(infos))
+	for _, info := range infos {
+		trip, err := database.LoadTrip(info.Name)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip)
+	}
+	return trips, nil
+}
+
+// tripShares gathers the members and the shares of the trips along with the currency they are settled in.
+// Members are the same across trips by their email. Shares are in the currency of their trip unless another
+// currency is given with --currency. Each share is converted once from its own currency at the rate of its date.
+func tripShares(c *cli.Context, trips []*database.Trip) ([]splitter.Member, []splitter.Share, string, error) {
+	members := make([]splitter.Member, 0)
+	seen := make(map[string]bool)
+	currencies := make(map[string]bool)
+	for _, trip := range trips {
+		for _, member := range trip.Members() {
+			if !seen[member.Email] {
+				seen[member.Email] = true
+				members = append(members, member)
+			}
+		}
+		currencies[strings.ToUpper(trip.Currency)] = true
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(c.String("currency")))
+	if currency != "" && currencies[""] {
+		return nil, nil, "", fmt.Errorf("Trip has no currency to convert to %s", currency)
+	}
+	if currency == "" {
+		if len(currencies) > 1 {
+			return nil, nil, "", splitter.ErrCurrencyNeeded
+		}
+		for tripCurrency := range currencies {
+			currency = tripCurrency
+		}
+	}
+
+	shares := make([]splitter.Share, 0)
+	for _, trip := range trips {
+		tripShares, err := trip.SharesIn(currency)
+		if errors.Is(err, money.ErrRateNotFound) {
+			return nil, nil, "", fmt.Errorf("%w, please add it with rates set", err)
+		}
+		if err != nil {
+			return nil, nil, "", err
+		}
+		shares = append(shares, tripShares...)
+	}
+	return members, shares, currency, nil
+}
+
+// printTripSuggestion prints the suggestions of every trip settled together along with the trips each amount comes from
+func printTripSuggestion(planSuggestion *splitter.PlanSuggestion, trips []*database.Trip, member string, currency string) {
+	if len(planSuggestion.Suggestions) == 0 {
+		fmt.Printf("%s  Everyone is settled\n", celebrate())
+	} else {
+		names := make(map[int64]string)
+		for _, trip := range trips {
+			names[trip.Id] = trip.Name
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FROM\tTO\tAMOUNT\tTRIPS")
+		for _, suggestion := range planSuggestion.Suggestions {
+			from := make([]string, len(suggestion.Trips))
+			for i, trip := range suggestion.Trips {
+				from[i] = fmt.Sprintf("%s %s", names[trip.Tripid], trip.Amount.Format(currency))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", suggestion.BMembername, suggestion.AMembername, suggestion.Amount.Format(currency), strings.Join(from, ", "))
+		}
+		w.Flush()
+	}
+
+	if member != "" {
+		fmt.Printf("%s  %s\n", celebrate(), planSuggestion.Brief)
+	}
+}
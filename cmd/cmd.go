@@ -2,19 +2,22 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
 	"github.com/urfave/cli"
 )
 
 func transactionFlags() []cli.Flag {
-	return []cli.Flag{
+	return append([]cli.Flag{
 		cli.StringFlag{
 			Name:  "name, n",
 			Value: "",
@@ -23,41 +26,77 @@ func transactionFlags() []cli.Flag {
 		cli.StringFlag{
 			Name:  "members, m",
 			Value: "",
-			Usage: "Comma seperated names eg. gus, walt, jesse etc. (Required)",
+			Usage: "Comma seperated names eg. gus, walt, jesse etc. Members in the trip on the date share the bill equally if not given (Optional for the equal split)",
 		},
 		cli.StringFlag{
 			Name:  "share, s",
 			Value: "",
-			Usage: "Comma seperated shares in the same order of members eg. 100, 50, 500 etc. or leave it blank if its is shared equally. (Optional if expense provided)",
+			Usage: "Comma seperated shares in the same order of members eg. 100, 50, 500 etc. or leave it blank if its is shared equally. Read as per --split (Optional if expense provided)",
 		},
 		cli.StringFlag{
 			Name:  "expense, e",
 			Value: "",
 			Usage: "Any valid amount (Optional if share provided)",
 		},
+		cli.StringFlag{
+			Name:  "paid-by, p",
+			Value: "",
+			Usage: "Member who paid the bill eg. gus or comma seperated payers with their amount eg. gus:60, walt:40 (Required)",
+		},
+		cli.StringFlag{
+			Name:  "date",
+			Value: "",
+			Usage: "Date of the transaction eg. 2026-01-31. Today if not given (Optional)",
+		},
+		currencyFlag("Currency of the amounts eg. EUR. It is converted to the trip's currency with the rate on the date. Trip's currency if not given (Optional)"),
 		cli.BoolFlag{
 			Name:  "delete, d",
 			Usage: "Delete everything",
 		},
-	}
+		cli.StringFlag{
+			Name:  "remainder, r",
+			Value: "",
+			Usage: "Who carries the remainder of the equal split: first, payer, round-robin or random. Trip's policy is used if not given (Optional)",
+		},
+		cli.Int64Flag{
+			Name:  "seed",
+			Usage: "Seed for the random remainder policy (Optional)",
+		},
+		splitFlag(),
+		createMembersFlag(),
+		tripFlag(),
+	}, itemFlags()...)
 }
 
 func suggestFlags() []cli.Flag {
 	return []cli.Flag{
 		cli.StringFlag{
-			Name:  "today, t",
+			Name:  "member, m",
+			Value: "",
+			Usage: "Name of the member whose brief has to be shown (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "solver",
 			Value: "",
-			Usage: "dev,playground, staging or prod",
+			Usage: "How the balances are settled: greedy or min-transfers for the least number of payments. Defaults to greedy (Optional)",
+		},
+		cli.BoolFlag{
+			Name:  "all-trips, a",
+			Usage: "Settle the balances of every trip together. Each payment shows the trips it comes from",
 		},
+		langFlag(),
+		currencyFlag("Currency to settle in, converted at the rates of the transaction dates. Needed when the trips are in different currencies (Optional)"),
+		tripFlag(),
 	}
 }
 
 //TransactionCmd used to create/delete transaction
 func TransactionCmd() cli.Command {
 	return cli.Command{
-		Name:  "transaction",
-		Usage: "Adds new transaction",
-		Flags: transactionFlags(),
+		Name:        "transaction",
+		Usage:       "Adds new transaction",
+		Flags:       transactionFlags(),
+		Subcommands: transactionSubcommands(),
 		// the action, or code that will be executed when
 		// we execute our `ns` command
 		Action: func(c *cli.Context) error {
@@ -66,13 +105,31 @@ func TransactionCmd() cli.Command {
 			members := c.String("members")
 			expense := c.String("expense")
 			share := c.String("share")
+			paidBy := c.String("paid-by")
 			delete := c.Bool("delete")
 
+			trip, err := tripName(c)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+
+			currency, err := tripCurrency(trip)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+
 			if delete {
-				_, yes := waitforinput("Do you really want to delete everything? (yes/no)")
-				if yes {
-					database.DeleteBucket("default")
+				_, yes := waitforinput(fmt.Sprintf("Do you really want to delete everything in %s? (yes/no)", trip))
+				if !yes {
+					return nil
 				}
+				if err := database.ClearTrip(trip); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				fmt.Printf("%s  success\n", celebrate())
 				return nil
 			}
 
@@ -81,29 +138,115 @@ func TransactionCmd() cli.Command {
 				return nil
 			}
 
-			if members == "" {
-				fmt.Printf("%s  Please give atleast one member name\n", devil())
+			names, err := tripMemberNames(c, trip)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
 				return nil
 			}
 
-			membersSlice := strings.Split(members, ",")
-			shareSlice := make([]float64, len(membersSlice))
+			date := time.Now()
+			if c.String("date") != "" {
+				if date, err = parseDate(c.String("date")); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+			}
 
-			if share == "" {
-				if expense == "" {
-					fmt.Printf("%s  Please provide either share or total expense. \n", devil())
+			// amounts are read in the currency of the transaction
+			transactionCurrency, err := amountCurrency(c, currency)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			if err := checkRate(transactionCurrency, currency, date); err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			currency = currencyOf(transactionCurrency, currency)
+
+			if isItemised(c) {
+				if paidBy == "" {
+					fmt.Printf("%s  Please give the member who paid the bill\n", devil())
+					return nil
+				}
+				if share != "" || c.String("split") != "" {
+					fmt.Printf("%s  Shares of the itemised bill are derived from its items\n", devil())
+					return nil
+				}
+				transaction, err := itemisedTransaction(c, names, trip, currency)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				transaction.Date = date
+				transaction.Currency = transactionCurrency
+				if err := names.checkActive(sharingNames(transaction.Shares), date); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				return saveTransaction(trip, transaction, currency)
+			}
+
+			if paidBy == "" {
+				fmt.Printf("%s  Please give the member who paid the bill\n", devil())
+				return nil
+			}
+
+			mode, err := splitter.ParseSplitMode(c.String("split"))
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			if c.String("split") == "" && share != "" {
+				mode = splitter.SplitExact
+			}
+
+			var membersSlice []string
+			if members == "" {
+				if mode != splitter.SplitEqual || share != "" {
+					fmt.Printf("%s  Please give atleast one member name\n", devil())
+					return nil
+				}
+				// everyone in the trip on the day shares the bill
+				if membersSlice = names.active(date); len(membersSlice) == 0 {
+					fmt.Printf("%s  No members in the trip on %s, please give the member names\n", devil(), date.Format(dateLayout))
 					return nil
 				}
-				expenseInteger, err := strconv.ParseFloat(expense, 64)
+			} else if membersSlice, err = names.resolveAll(strings.Split(members, ",")); err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			if err := names.checkActive(membersSlice, date); err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			if paidBy, err = names.resolvePaidBy(paidBy); err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			shareSlice := make([]money.Money, len(membersSlice))
+
+			var expenseInteger money.Money
+			if expense != "" {
+				var err error
+				expenseInteger, err = money.Parse(expense, currency)
 				if err != nil {
 					fmt.Printf("%s  Please enter valid expense\n", devil())
 					return nil
 				}
-				totalMembers := len(membersSlice)
-				share := expenseInteger / float64(totalMembers)
-				for i := range membersSlice {
-					shareSlice[i] = share
+			}
+
+			var weights []int64
+			if share == "" {
+				if mode != splitter.SplitEqual {
+					fmt.Printf("%s  Please give the shares for the %s split\n", devil(), mode)
+					return nil
+				}
+				if expense == "" {
+					fmt.Printf("%s  Please provide either share or total expense. \n", devil())
+					return nil
 				}
+				// shares are split equally by the splitter once the payers are known
 			} else {
 				shares := strings.Split(share, ",")
 
@@ -111,30 +254,88 @@ func TransactionCmd() cli.Command {
 					fmt.Printf("%s  Given members and their shares are not matching\n", devil())
 					return nil
 				}
+				if mode == splitter.SplitEqual {
+					fmt.Printf("%s  Shares are not needed for the equal split\n", devil())
+					return nil
+				}
 
-				for i := range membersSlice {
-					eachShare := shares[i]
-					eachShareInteger, err := strconv.ParseFloat(eachShare, 64)
-					if err != nil {
-						fmt.Printf("%s  Please enter share amount\n", devil())
+				weights, err = parseWeights(mode, shares, currency)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				if expense == "" {
+					if mode != splitter.SplitExact {
+						fmt.Printf("%s  Please provide the total expense for the %s split\n", devil(), mode)
 						return nil
 					}
-					shareSlice[i] = eachShareInteger
+					for _, weight := range weights {
+						expenseInteger = expenseInteger + money.FromMinor(weight)
+					}
 				}
 			}
 
-			time.Sleep(1 * time.Second)
-			err := database.NewTrip("default", transactionName, membersSlice, shareSlice)
+			sharingMembers := len(membersSlice)
+			membersSlice, shareSlice, paidSlice, err := parsePaidBy(paidBy, expenseInteger, currency, membersSlice, shareSlice)
 			if err != nil {
 				fmt.Printf("%s  %s\n", devil(), err.Error())
 				return nil
 			}
-			fmt.Printf("%s  success\n", celebrate())
-			return nil
+
+			transaction := database.Transaction{
+				Name:     transactionName,
+				Amount:   expenseInteger,
+				Currency: transactionCurrency,
+				Date:     date,
+				Shares:   make([]database.Share, len(membersSlice)),
+			}
+			sharing := make([]int, 0, sharingMembers)
+			for i, member := range membersSlice {
+				transaction.Shares[i] = database.Share{
+					Member: member,
+					Amount: shareSlice[i],
+					Paid:   paidSlice[i],
+				}
+				if i < sharingMembers { // payers outside the members don't share
+					sharing = append(sharing, i)
+				}
+			}
+
+			remainder, err := parseRemainder(c, trip, -1)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			if err := resolveShares(&transaction, sharing, mode, weights, remainder); err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			transaction.Shares = splitAutoShares(transaction.Shares, expenseInteger, remainder)
+
+			return saveTransaction(trip, transaction, currency)
 		},
 	}
 }
 
+// saveTransaction validates the shares of the new transaction and adds it to the trip
+func saveTransaction(trip string, transaction database.Transaction, currency string) error {
+	members, shares, paid := shareSlices(transaction.Shares)
+	if ok, reason := validateShares(members, shares, paid, transaction.Amount); !ok {
+		fmt.Printf("%s  %s\n", devil(), reason)
+		return nil
+	}
+	reportRemainder(transaction.Shares, currency)
+
+	time.Sleep(1 * time.Second)
+	id, err := database.NewTrip(trip, transaction)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	fmt.Printf("%s  success (id %d)\n", celebrate(), id)
+	return nil
+}
+
 //SuggestCmd suggests user share
 func SuggestCmd() cli.Command {
 	return cli.Command{
@@ -144,27 +345,165 @@ func SuggestCmd() cli.Command {
 		// the action, or code that will be executed when
 		// we execute our `ns` command
 		Action: func(c *cli.Context) error {
-			// a simple lookup function
-			today := c.String("today")
-			if today != "" {
+			trips, err := loadTrips(c)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			member := ""
+			if c.String("member") != "" {
+				if member, err = resolveTripsMember(c, trips, c.String("member")); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+			}
 
+			var tripId int64
+			transactions := 0
+			for _, trip := range trips {
+				tripId = trip.Id
+				transactions = transactions + len(trip.Transactions)
+			}
+			if transactions == 0 {
+				fmt.Printf("%s  No transactions to settle\n", devil())
 				return nil
 			}
 
-			_, result := waitforinput("Apply blueprint? (yes/no)")
-			if result {
-				fmt.Printf("got the input")
+			members, shares, currency, err := tripShares(c, trips)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			var total money.Money
+			for _, share := range shares {
+				total = total + share.Share
 			}
 
-			time.Sleep(1 * time.Second)
-			// we log the results to our console
-			// using a trusty fmt.Println statement
-			fmt.Printf("success")
+			solver, err := splitter.ParseSolver(c.String("solver"))
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+
+			language, err := briefLanguage(c, trips, member)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+
+			opts := []splitter.Option{splitter.WithCurrency(currency), splitter.WithSolver(solver), splitter.WithLanguage(language)}
+			if c.Bool("all-trips") {
+				tripId = 0
+				opts = append(opts, splitter.WithAllTrips())
+			}
+			planSuggestion := splitter.CreateTotalSuggestion(tripId, total, members, member, shares, opts...)
+			if c.Bool("all-trips") {
+				printTripSuggestion(planSuggestion, trips, member, currency)
+				return nil
+			}
+			printSuggestion(planSuggestion, member, currency)
 			return nil
 		},
 	}
 }
 
+func printSuggestion(planSuggestion *splitter.PlanSuggestion, member string, currency string) {
+	if len(planSuggestion.Suggestions) == 0 {
+		fmt.Printf("%s  Everyone is settled\n", celebrate())
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FROM\tTO\tAMOUNT")
+		for _, suggestion := range planSuggestion.Suggestions {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", suggestion.BMembername, suggestion.AMembername, suggestion.Amount.Format(currency))
+		}
+		w.Flush()
+	}
+
+	if member != "" {
+		fmt.Printf("%s  %s\n", celebrate(), planSuggestion.Brief)
+	}
+}
+
+// parsePaidBy reads either a single payer who paid the whole bill or comma seperated payer:amount pairs.
+// Payers who are not part of the members are added with a zero share.
+func parsePaidBy(paidBy string, billAmount money.Money, currency string, members []string, shares []money.Money) ([]string, []money.Money, []money.Money, error) {
+	paid := make([]money.Money, len(members))
+	payers := strings.Split(paidBy, ",")
+	for _, payer := range payers {
+		name := payer
+		amount := billAmount
+		if strings.Contains(payer, ":") {
+			parts := strings.SplitN(payer, ":", 2)
+			name = parts[0]
+			var err error
+			amount, err = money.Parse(parts[1], currency)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("Please enter valid paid amount for %s", strings.TrimSpace(name))
+			}
+		} else if len(payers) > 1 {
+			return nil, nil, nil, errors.New("Please give the paid amount of each payer eg. gus:60, walt:40")
+		}
+
+		index := indexOfMember(members, name)
+		if index == -1 {
+			members = append(members, name)
+			shares = append(shares, 0)
+			paid = append(paid, 0)
+			index = len(members) - 1
+		}
+		paid[index] = paid[index] + amount
+	}
+	return members, shares, paid, nil
+}
+
+// applyPaidBy sets what each member paid as per --paid-by. Payers without a share are added with a zero share.
+func applyPaidBy(names *memberNames, shares []database.Share, paidBy string, billAmount money.Money, currency string) ([]database.Share, error) {
+	paidBy, err := names.resolvePaidBy(paidBy)
+	if err != nil {
+		return shares, err
+	}
+	members, amounts, _ := shareSlices(shares)
+	members, amounts, paid, err := parsePaidBy(paidBy, billAmount, currency, members, amounts)
+	if err != nil {
+		return shares, err
+	}
+	for i := range members {
+		if i >= len(shares) { // payers outside the members don't share
+			shares = append(shares, database.Share{Member: strings.TrimSpace(members[i]), Amount: amounts[i]})
+		}
+		shares[i].Paid = paid[i]
+	}
+	return shares, nil
+}
+
+func indexOfMember(members []string, name string) int {
+	for i, member := range members {
+		if strings.TrimSpace(member) == strings.TrimSpace(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateShares makes sure the total paid and the total share are matching the bill amount
+func validateShares(members []string, shares []money.Money, paid []money.Money, billAmount money.Money) (bool, string) {
+	splitterShares := make([]splitter.Share, len(members))
+	for i, member := range members {
+		splitterShares[i] = splitter.Share{
+			Memberemail:     member,
+			Membername:      member,
+			Benefactoremail: member,
+			Share:           shares[i],
+			Paid:            paid[i],
+		}
+	}
+	result := splitter.ValidateSharesForBill(billAmount, nil, splitterShares)
+	if !result.Valid() {
+		return false, result.Error()
+	}
+	return true, ""
+}
+
 func waitforinput(title string) (string, bool) {
 	yellow := color.New(color.FgYellow).SprintFunc()
 	reader := bufio.NewReader(os.Stdin)
@@ -16,7 +16,7 @@ func devil() string {
 
 func unquoteCodePoint(s string) (string, error) {
 	r, err := strconv.ParseInt(strings.TrimPrefix(s, "\\U"), 16, 32)
-	return string(r), err
+	return string(rune(r)), err
 }
 
 func validating() string {
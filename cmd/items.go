@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+var errItemFormat = errors.New("Please give the item as name:price:members or name:price:quantity:members eg. Pizza:18.50:gus,walt")
+
+func itemFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "item, i",
+			Usage: "Line item of the bill as name:price:members or name:price:quantity:members eg. Pizza:18.50:gus,walt. Repeat it for each item, leave the members empty if everyone had it (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "items-file",
+			Value: "",
+			Usage: "File with one item per line in the same format as --item. Lines starting with # are skipped (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "tax",
+			Value: "",
+			Usage: "Tax of the itemised bill as an amount or a percent of the items eg. 4.05 or 8.875% (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "service",
+			Value: "",
+			Usage: "Service charge of the itemised bill as an amount or a percent of the items eg. 10% (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "tip",
+			Value: "",
+			Usage: "Tip of the itemised bill as an amount or a percent of the items eg. 15% (Optional)",
+		},
+	}
+}
+
+// isItemised tells whether the items of the bill are given in the flags
+func isItemised(c *cli.Context) bool {
+	return len(c.StringSlice("item")) > 0 || c.String("items-file") != ""
+}
+
+// itemisedTransaction creates the transaction from the items given in the flags
+func itemisedTransaction(c *cli.Context, names *memberNames, tripName string, currency string) (database.Transaction, error) {
+	transaction := database.Transaction{Name: c.String("name")}
+	if err := applyBill(c, names, &transaction, currency); err != nil {
+		return transaction, err
+	}
+
+	transaction.Amount = transaction.Bill().Total()
+	if c.String("expense") != "" {
+		expense, err := money.Parse(c.String("expense"), currency)
+		if err != nil {
+			return transaction, errors.New("Please enter valid expense")
+		}
+		if expense != transaction.Amount {
+			return transaction, fmt.Errorf("Expense %s is not matching the total of the items %s", expense.Format(currency), transaction.Amount.Format(currency))
+		}
+	}
+
+	var err error
+	if transaction.Shares, err = applyPaidBy(names, nil, c.String("paid-by"), transaction.Amount, currency); err != nil {
+		return transaction, err
+	}
+
+	remainder, err := parseRemainder(c, tripName, -1)
+	if err != nil {
+		return transaction, err
+	}
+	return transaction, resolveItems(&transaction, remainder)
+}
+
+// editItems applies the changes of the items and the charges on the itemised transaction
+func editItems(c *cli.Context, names *memberNames, tripName string, transaction database.Transaction, currency string) (database.Transaction, error) {
+	for _, flag := range []string{"members", "share", "expense", "split"} {
+		if c.String(flag) != "" {
+			return transaction, fmt.Errorf("Itemised transaction is changed with --item, --items-file, --tax, --service and --tip, not with --%s", flag)
+		}
+	}
+
+	amount := transaction.Amount
+	if err := applyBill(c, names, &transaction, currency); err != nil {
+		return transaction, err
+	}
+	transaction.Amount = transaction.Bill().Total()
+
+	if c.String("paid-by") != "" {
+		var err error
+		if transaction.Shares, err = applyPaidBy(names, transaction.Shares, c.String("paid-by"), transaction.Amount, currency); err != nil {
+			return transaction, err
+		}
+	} else if payers := payerIndexes(transaction.Shares); amount != transaction.Amount && len(payers) == 1 {
+		// the only payer paid the new amount too
+		transaction.Shares[payers[0]].Paid = transaction.Amount
+	}
+
+	remainder, err := parseRemainder(c, tripName, transaction.Id)
+	if err != nil {
+		return transaction, err
+	}
+	return transaction, resolveItems(&transaction, remainder)
+}
+
+// applyBill reads the items and the charges given in the flags into the transaction.
+// The items and charges which are not given are left as they are.
+func applyBill(c *cli.Context, names *memberNames, transaction *database.Transaction, currency string) error {
+	if isItemised(c) {
+		items, err := parseItems(c, currency)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			if items[i].Members, err = names.resolveAll(items[i].Members); err != nil {
+				return err
+			}
+		}
+		transaction.Items = items
+	}
+	if len(transaction.Items) == 0 {
+		return splitter.ErrItemsNeeded
+	}
+
+	// items without members are shared by everyone
+	var everyone []string
+	if c.String("members") != "" {
+		members, err := names.resolveAll(strings.Split(c.String("members"), ","))
+		if err != nil {
+			return err
+		}
+		everyone = addMember(everyone, members...)
+	}
+	for _, item := range transaction.Items {
+		everyone = addMember(everyone, item.Members...)
+	}
+	for i := range transaction.Items {
+		if len(transaction.Items[i].Members) == 0 {
+			transaction.Items[i].Members = everyone
+		}
+	}
+
+	subtotal := transaction.Bill().Subtotal()
+	charges := []struct {
+		flag   string
+		amount *money.Money
+	}{
+		{"tax", &transaction.Tax},
+		{"service", &transaction.Service},
+		{"tip", &transaction.Tip},
+	}
+	for _, charge := range charges {
+		if c.String(charge.flag) == "" {
+			continue
+		}
+		amount, err := parseCharge(c.String(charge.flag), subtotal, currency)
+		if err != nil {
+			return fmt.Errorf("Please enter valid %s eg. 4.05 or 8.875%%", charge.flag)
+		}
+		*charge.amount = amount
+	}
+	return nil
+}
+
+// parseItems reads the items given with --item followed by the ones in --items-file
+func parseItems(c *cli.Context, currency string) ([]database.Item, error) {
+	lines := append([]string{}, c.StringSlice("item")...)
+	if c.String("items-file") != "" {
+		file, err := os.Open(c.String("items-file"))
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			lines = append(lines, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]database.Item, len(lines))
+	for i, line := range lines {
+		item, err := parseItem(line, currency)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// parseItem reads name:price:members or name:price:quantity:members
+func parseItem(line string, currency string) (database.Item, error) {
+	item := database.Item{Quantity: 1}
+	parts := strings.Split(line, ":")
+	if len(parts) != 3 && len(parts) != 4 {
+		return item, errItemFormat
+	}
+
+	item.Name = strings.TrimSpace(parts[0])
+	if item.Name == "" {
+		return item, errItemFormat
+	}
+	price, err := money.Parse(parts[1], currency)
+	if err != nil || price < 0 {
+		return item, fmt.Errorf("Please enter valid price for %s", item.Name)
+	}
+	item.Price = price
+	if len(parts) == 4 {
+		quantity, err := strconv.ParseInt(strings.TrimSpace(parts[2]), 10, 64)
+		if err != nil || quantity < 1 {
+			return item, fmt.Errorf("Please enter valid quantity for %s", item.Name)
+		}
+		item.Quantity = quantity
+	}
+	item.Members = addMember(nil, strings.Split(parts[len(parts)-1], ",")...)
+	return item, nil
+}
+
+// parseCharge reads the charge either as an amount or as a percent of the subtotal eg. 8.875%.
+// The percent is kept exact and the charge is rounded once.
+func parseCharge(value string, subtotal money.Money, currency string) (money.Money, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "%") {
+		number := strings.TrimSpace(strings.TrimSuffix(value, "%"))
+		percent, ok := new(big.Rat).SetString(number)
+		if !ok || percent.Sign() < 0 || strings.ContainsAny(number, "/eE") {
+			return 0, errors.New("Invalid percent")
+		}
+		return subtotal.Scale(percent.Quo(percent, big.NewRat(100, 1))), nil
+	}
+	amount, err := money.Parse(value, currency)
+	if err != nil || amount < 0 {
+		return 0, errors.New("Invalid amount")
+	}
+	return amount, nil
+}
+
+// resolveItems derives the shares of the itemised transaction from its items.
+// Members who paid without having any item are kept with a zero share.
+func resolveItems(transaction *database.Transaction, remainder splitter.Remainder) error {
+	if remainder.Policy == splitter.RemainderPayer && remainder.Payer == "" {
+		var mostPaid money.Money
+		for _, share := range transaction.Shares {
+			if share.Paid > mostPaid {
+				mostPaid, remainder.Payer = share.Paid, share.Member
+			}
+		}
+	}
+
+	members, amounts, err := splitter.SplitItems(transaction.Bill(), remainder)
+	if err != nil {
+		return err
+	}
+
+	shares := make([]database.Share, len(members))
+	for i, member := range members {
+		shares[i] = database.Share{Member: member, Amount: amounts[i]}
+		if index := indexOfShare(transaction.Shares, member); index != -1 {
+			shares[i].Id = transaction.Shares[index].Id
+			shares[i].Paid = transaction.Shares[index].Paid
+		}
+	}
+	for _, share := range transaction.Shares {
+		if share.Paid != 0 && indexOfMember(members, share.Member) == -1 {
+			share.Amount, share.Auto, share.Weight = 0, false, 0
+			shares = append(shares, share)
+		}
+	}
+
+	transaction.Shares = shares
+	transaction.Amount = transaction.Bill().Total()
+	transaction.Split = splitter.SplitItemised.String()
+	return nil
+}
+
+func indexOfShare(shares []database.Share, member string) int {
+	for i, share := range shares {
+		if strings.TrimSpace(share.Member) == strings.TrimSpace(member) {
+			return i
+		}
+	}
+	return -1
+}
+
+// addMember appends the trimmed names which are not in the list already
+func addMember(members []string, names ...string) []string {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name != "" && indexOfMember(members, name) == -1 {
+			members = append(members, name)
+		}
+	}
+	return members
+}
+
+func printItems(transaction *database.Transaction, currency string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ITEM\tQTY\tPRICE\tMEMBERS")
+	for _, item := range transaction.Items {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", item.Name, item.Quantity, item.Price.Format(currency), strings.Join(item.Members, ", "))
+	}
+	w.Flush()
+
+	if transaction.Tax != 0 {
+		fmt.Printf("Tax:     %s\n", transaction.Tax.Format(currency))
+	}
+	if transaction.Service != 0 {
+		fmt.Printf("Service: %s\n", transaction.Service.Format(currency))
+	}
+	if transaction.Tip != 0 {
+		fmt.Printf("Tip:     %s\n", transaction.Tip.Format(currency))
+	}
+}
@@ -0,0 +1,433 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+func createMembersFlag() cli.Flag {
+	return cli.BoolFlag{
+		Name:  "create-members",
+		Usage: "Add the names which are not members of the trip yet instead of rejecting them",
+	}
+}
+
+//MemberCmd used to add/list/edit/remove the members of a trip
+func MemberCmd() cli.Command {
+	return cli.Command{
+		Name:  "member",
+		Usage: "Manages the members of the trip",
+		Subcommands: []cli.Command{
+			{
+				Name:      "add",
+				Usage:     "Adds new member to the trip",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "email, e",
+						Value: "",
+						Usage: "Email of the member (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "avatar",
+						Value: "",
+						Usage: "Url or path of the member's picture (Optional)",
+					},
+					cli.StringSliceFlag{
+						Name:  "alias, a",
+						Usage: "Other name the member is called by. Repeat it for each alias (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "joined",
+						Value: "",
+						Usage: "Date the member joined the trip eg. 2026-01-31. Member is part of the trip from the start if not given (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "lang",
+						Value: "",
+						Usage: fmt.Sprintf("Language the member reads the briefs in: %s (Optional)", strings.Join(splitter.Languages, ", ")),
+					},
+					tripFlag(),
+				},
+				Action: func(c *cli.Context) error {
+					info, err := tripInfo(c)
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					member := database.Member{
+						Name:    c.Args().First(),
+						Email:   c.String("email"),
+						Avatar:  c.String("avatar"),
+						Aliases: c.StringSlice("alias"),
+					}
+					if member.Language, err = parseLanguage(c.String("lang")); err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					if c.String("joined") != "" {
+						if member.Joined, err = parseDate(c.String("joined")); err != nil {
+							fmt.Printf("%s  %s\n", devil(), err.Error())
+							return nil
+						}
+					}
+					if err := database.AddMember(info.Name, member); err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+			{
+				Name:   "list",
+				Usage:  "Lists the members of the trip",
+				Flags:  []cli.Flag{tripFlag()},
+				Action: listMembers,
+			},
+			{
+				Name:      "edit",
+				Usage:     "Edits the member. Renaming the member renames it in the transactions too",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "name, n",
+						Value: "",
+						Usage: "New name of the member (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "email, e",
+						Value: "",
+						Usage: "New email of the member (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "avatar",
+						Value: "",
+						Usage: "New url or path of the member's picture (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "joined",
+						Value: "",
+						Usage: "New date the member joined the trip eg. 2026-01-31 (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "lang",
+						Value: "",
+						Usage: fmt.Sprintf("New language the member reads the briefs in: %s (Optional)", strings.Join(splitter.Languages, ", ")),
+					},
+					tripFlag(),
+				},
+				Action: editMember,
+			},
+			{
+				Name:      "join",
+				Usage:     "Sets the date the member joined the trip. Member who left rejoins the trip",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "date",
+						Value: "",
+						Usage: "Date the member joined the trip eg. 2026-01-31. The date the member joined before is kept if not given (Optional)",
+					},
+					tripFlag(),
+				},
+				Action: func(c *cli.Context) error {
+					return changeMembership(c, time.Time{}, database.JoinMember)
+				},
+			},
+			{
+				Name:      "leave",
+				Usage:     "Sets the last date of the member in the trip. Member has to be settled before leaving",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "date",
+						Value: "",
+						Usage: "Last date of the member in the trip eg. 2026-01-31. Today if not given (Optional)",
+					},
+					tripFlag(),
+				},
+				Action: func(c *cli.Context) error {
+					return changeMembership(c, time.Now(), database.LeaveMember)
+				},
+			},
+			{
+				Name:      "remove",
+				Usage:     "Removes the member who has no transactions in the trip",
+				ArgsUsage: "<name>",
+				Flags:     []cli.Flag{tripFlag()},
+				Action: func(c *cli.Context) error {
+					info, err := tripInfo(c)
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					name := database.NormaliseName(c.Args().First())
+					_, yes := waitforinput(fmt.Sprintf("Do you really want to remove %s from %s? (yes/no)", name, info.Name))
+					if !yes {
+						return nil
+					}
+					if err := database.RemoveMember(info.Name, name); err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+			{
+				Name:      "alias",
+				Usage:     "Lets the member be called by another name",
+				ArgsUsage: "<name> <alias>",
+				Flags:     []cli.Flag{tripFlag()},
+				Action: func(c *cli.Context) error {
+					info, err := tripInfo(c)
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					if err := database.AddAlias(info.Name, c.Args().Get(0), c.Args().Get(1)); err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// changeMembership lets the member join or leave the trip on the date given with --date
+func changeMembership(c *cli.Context, date time.Time, change func(tripName, name string, date time.Time) error) error {
+	info, err := tripInfo(c)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	if c.String("date") != "" {
+		if date, err = parseDate(c.String("date")); err != nil {
+			fmt.Printf("%s  %s\n", devil(), err.Error())
+			return nil
+		}
+	}
+
+	err = change(info.Name, c.Args().First(), date)
+	var unsettled *database.UnsettledError
+	if errors.As(err, &unsettled) {
+		if unsettled.Net < 0 {
+			fmt.Printf("%s  %s owes %s, please settle it before leaving\n", devil(), unsettled.Member, (-unsettled.Net).Format(info.Currency))
+		} else {
+			fmt.Printf("%s  %s is owed %s, please settle it before leaving\n", devil(), unsettled.Member, unsettled.Net.Format(info.Currency))
+		}
+		return nil
+	}
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	fmt.Printf("%s  success\n", celebrate())
+	return nil
+}
+
+func listMembers(c *cli.Context) error {
+	info, err := tripInfo(c)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	members, err := database.Members(info.Name)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	if len(members) == 0 {
+		fmt.Printf("%s  No members found\n", devil())
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tEMAIL\tALIASES\tJOINED\tLEFT\tLANG\tAVATAR")
+	for _, member := range members {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", member.Name, orDash(member.Email), orDash(strings.Join(member.Aliases, ", ")), formatDay(member.Joined), formatDay(member.Left), orDash(member.Language), orDash(member.Avatar))
+	}
+	w.Flush()
+	return nil
+}
+
+func editMember(c *cli.Context) error {
+	info, err := tripInfo(c)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	members, err := database.Members(info.Name)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	member, ok := database.FindMember(members, c.Args().First())
+	if !ok {
+		fmt.Printf("%s  %s\n", devil(), database.ErrMemberNotFound.Error())
+		return nil
+	}
+
+	name := member.Name
+	if c.String("name") != "" {
+		member.Name = c.String("name")
+	}
+	if c.String("email") != "" {
+		member.Email = c.String("email")
+	}
+	if c.String("avatar") != "" {
+		member.Avatar = c.String("avatar")
+	}
+	if c.String("joined") != "" {
+		if member.Joined, err = parseDate(c.String("joined")); err != nil {
+			fmt.Printf("%s  %s\n", devil(), err.Error())
+			return nil
+		}
+	}
+	if c.String("lang") != "" {
+		if member.Language, err = parseLanguage(c.String("lang")); err != nil {
+			fmt.Printf("%s  %s\n", devil(), err.Error())
+			return nil
+		}
+	}
+	if err := database.EditMember(info.Name, name, member); err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	fmt.Printf("%s  success\n", celebrate())
+	return nil
+}
+
+// memberNames resolves the names typed in the flags to the members of the trip
+type memberNames struct {
+	members []database.Member
+	create  bool // unknown names are taken as new members
+}
+
+// tripMemberNames loads the members of the trip. New names are accepted only with --create-members.
+func tripMemberNames(c *cli.Context, tripName string) (*memberNames, error) {
+	members, err := database.Members(tripName)
+	if err != nil {
+		return nil, err
+	}
+	return &memberNames{members: members, create: c.Bool("create-members")}, nil
+}
+
+// resolve returns the name of the member called by the name or one of its aliases
+func (names *memberNames) resolve(name string) (string, error) {
+	name = database.NormaliseName(name)
+	if name == "" {
+		return "", database.ErrInvalidMemberName
+	}
+	if member, ok := database.FindMember(names.members, name); ok {
+		return member.Name, nil
+	}
+	if !names.create {
+		return "", fmt.Errorf("%s is not a member of the trip, add it with member add or give --create-members", name)
+	}
+	if strings.ContainsAny(name, ",:") {
+		return "", database.ErrInvalidMemberName
+	}
+	names.members = append(names.members, database.Member{Name: name})
+	return name, nil
+}
+
+// active returns the members who are in the trip on the date
+func (names *memberNames) active(date time.Time) []string {
+	active := make([]string, 0)
+	for _, member := range names.members {
+		if member.Active(date) {
+			active = append(active, member.Name)
+		}
+	}
+	return active
+}
+
+// checkActive makes sure the members are in the trip on the date
+func (names *memberNames) checkActive(list []string, date time.Time) error {
+	for _, name := range list {
+		member, ok := database.FindMember(names.members, name)
+		if ok && !member.Active(date) {
+			return fmt.Errorf("%s is not in the trip on %s", member.Name, date.Format(dateLayout))
+		}
+	}
+	return nil
+}
+
+// resolveAll resolves every name of the list
+func (names *memberNames) resolveAll(list []string) ([]string, error) {
+	resolved := make([]string, len(list))
+	for i, name := range list {
+		var err error
+		if resolved[i], err = names.resolve(name); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// resolvePaidBy resolves the payers of --paid-by keeping their amounts
+func (names *memberNames) resolvePaidBy(paidBy string) (string, error) {
+	payers := strings.Split(paidBy, ",")
+	for i, payer := range payers {
+		parts := strings.SplitN(payer, ":", 2)
+		name, err := names.resolve(parts[0])
+		if err != nil {
+			return "", err
+		}
+		parts[0] = name
+		payers[i] = strings.Join(parts, ":")
+	}
+	return strings.Join(payers, ","), nil
+}
+
+func langFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:   "lang",
+		Value:  "",
+		Usage:  fmt.Sprintf("Language of the brief: %s. Defaults to the language of the member (Optional)", strings.Join(splitter.Languages, ", ")),
+		EnvVar: "EXPENSE_LANG",
+	}
+}
+
+// parseLanguage checks the language is in the catalog of the briefs. Empty is left to the default.
+func parseLanguage(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	// tags like de-DE resolve to their language
+	language, ok := splitter.Language(value)
+	if !ok {
+		return "", fmt.Errorf("Unknown language %s, use one of %s", value, strings.Join(splitter.Languages, ", "))
+	}
+	return language, nil
+}
+
+// briefLanguage is the language given with --lang, else the language of the member in the first trip knowing the member
+func briefLanguage(c *cli.Context, trips []*database.Trip, member string) (string, error) {
+	if c.String("lang") != "" {
+		return parseLanguage(c.String("lang"))
+	}
+	for _, trip := range trips {
+		members, err := database.Members(trip.Name)
+		if err != nil {
+			return "", err
+		}
+		if found, ok := database.FindMember(members, member); ok && found.Language != "" {
+			return found.Language, nil
+		}
+	}
+	return splitter.DefaultLanguage, nil
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/notifier"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+func notifyFlags(flags ...cli.Flag) []cli.Flag {
+	flags = append(flags,
+		cli.StringFlag{
+			Name:   "channel",
+			Value:  "stdout",
+			Usage:  "Comma separated channels to notify through: smtp, webhook, file and stdout",
+			EnvVar: "EXPENSE_NOTIFY_CHANNELS",
+		},
+		cli.StringFlag{
+			Name:   "webhook",
+			Value:  "",
+			Usage:  "URL the notifications are posted to as JSON by the webhook channel",
+			EnvVar: "EXPENSE_WEBHOOK_URL",
+		},
+		cli.StringFlag{
+			Name:   "file",
+			Value:  "notifications.txt",
+			Usage:  "File the notifications are appended to by the file channel",
+			EnvVar: "EXPENSE_NOTIFY_FILE",
+		},
+		cli.StringFlag{
+			Name:  "member, m",
+			Value: "",
+			Usage: "Notify the member only. Every member concerned is notified if not given (Optional)",
+		},
+		tripFlag(),
+	)
+	return append(flags, mailFlags()...)
+}
+
+//NotifyCmd notifies the members of the trip about its expenses, settlements and balances
+func NotifyCmd() cli.Command {
+	return cli.Command{
+		Name:  "notify",
+		Usage: "Notifies the members through email, webhook, file or stdout",
+		Subcommands: []cli.Command{
+			{
+				Name:      "expense",
+				Usage:     "Notifies the members sharing the transaction about it",
+				ArgsUsage: "<id>",
+				Flags:     notifyFlags(),
+				Action: func(c *cli.Context) error {
+					id, err := transactionID(c)
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					return notifyMembers(c, notifier.NewExpense, func(trip *database.Trip, member string) (interface{}, bool, error) {
+						transaction, err := database.FindTransaction(trip.Name, id)
+						if err != nil {
+							return nil, false, err
+						}
+						if indexOfShare(transaction.Shares, member) == -1 {
+							return nil, false, nil
+						}
+						data := notifier.Expense{Trip: trip.Name, Member: member, Item: notifyItem(trip, *transaction, member)}
+						for _, payer := range transaction.Payers() {
+							data.PaidBy = append(data.PaidBy, payer.Member)
+						}
+						return data, true, nil
+					})
+				},
+			},
+			{
+				Name:      "settled",
+				Usage:     "Notifies the members of the settlement between them",
+				ArgsUsage: "<id>",
+				Flags:     notifyFlags(),
+				Action: func(c *cli.Context) error {
+					id, err := strconv.ParseInt(c.Args().First(), 10, 64)
+					if err != nil {
+						fmt.Printf("%s  Please give the settlement id\n", devil())
+						return nil
+					}
+					return notifyMembers(c, notifier.Settled, func(trip *database.Trip, member string) (interface{}, bool, error) {
+						for _, settlement := range trip.Settlements {
+							if settlement.Id != id {
+								continue
+							}
+							if settlement.From != member && settlement.To != member {
+								return nil, false, nil
+							}
+							return notifier.Settlement{Trip: trip.Name, Member: member, Payment: notifyPayment(trip, settlement)}, true, nil
+						}
+						return nil, false, database.ErrSettlementNotFound
+					})
+				},
+			},
+			{
+				Name:  "owe",
+				Usage: "Notifies the members who owe money what they have to pay",
+				Flags: notifyFlags(currencyFlag("Currency to ask the amounts in, converted at the rates of the transaction dates (Optional)")),
+				Action: func(c *cli.Context) error {
+					var debts map[string][]splitter.Suggestion
+					var currency string
+					return notifyMembers(c, notifier.YouOwe, func(trip *database.Trip, member string) (interface{}, bool, error) {
+						if debts == nil {
+							planSuggestion, _, settleCurrency, err := tripSuggestion(c, trip)
+							if err != nil {
+								return nil, false, err
+							}
+							debts, currency = debtsByMember(planSuggestion.Suggestions), settleCurrency
+						}
+						if len(debts[member]) == 0 {
+							return nil, false, nil
+						}
+						return oweData(trip, database.Member{Name: member}, debts[member], currency), true, nil
+					})
+				},
+			},
+			{
+				Name:  "summary",
+				Usage: "Sends each member the summary of the transactions of the last days and the balances",
+				Flags: notifyFlags(
+					cli.IntFlag{
+						Name:  "days, d",
+						Value: 7,
+						Usage: "Days the summary covers",
+					},
+					currencyFlag("Currency to show the balances in, converted at the rates of the transaction dates (Optional)"),
+				),
+				Action: func(c *cli.Context) error {
+					to := time.Now()
+					from := to.AddDate(0, 0, -c.Int("days"))
+					var summary *notifier.Summary
+					var debts map[string][]splitter.Suggestion
+					var currency string
+					return notifyMembers(c, notifier.WeeklySummary, func(trip *database.Trip, member string) (interface{}, bool, error) {
+						if summary == nil {
+							planSuggestion, balances, settleCurrency, err := tripSuggestion(c, trip)
+							if err != nil {
+								return nil, false, err
+							}
+							debts, currency = debtsByMember(planSuggestion.Suggestions), settleCurrency
+							summary = &notifier.Summary{Trip: trip.Name, From: from, To: to}
+							for _, balance := range balances {
+								if balance.Net != 0 {
+									summary.Balances = append(summary.Balances, notifier.Balance{Member: balance.Membername, Amount: formatAmount(balance.Net.Abs(), currency, ""), Owes: balance.Net < 0})
+								}
+							}
+						}
+
+						data := *summary
+						data.Member = member
+						spent := make(map[string]money.Money)
+						for _, transaction := range trip.Transactions {
+							if transaction.Date.Before(from) || transaction.Date.After(to) {
+								continue
+							}
+							data.Items = append(data.Items, notifyItem(trip, transaction, member))
+							spent[currencyOf(transaction.Currency, trip.Currency)] += transaction.Amount
+						}
+						data.Spent = formatSpent(spent)
+						for _, debt := range debts[member] {
+							data.Debts = append(data.Debts, notifier.Debt{To: debt.AMembername, Amount: formatAmount(debt.Amount, currency, "")})
+						}
+						return data, true, nil
+					})
+				},
+			},
+		},
+	}
+}
+
+// notifyMembers sends the notification of the kind to every member of the trip, or the one given with --member.
+// data returns the data of the notification for the member and whether the member is notified at all.
+func notifyMembers(c *cli.Context, kind notifier.Kind, data func(trip *database.Trip, member string) (interface{}, bool, error)) error {
+	notify, err := newNotifier(c)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	trip, err := loadTrip(c)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	names, err := tripMemberNames(c, trip.Name)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	only := ""
+	if c.String("member") != "" {
+		if only, err = names.resolve(c.String("member")); err != nil {
+			fmt.Printf("%s  %s\n", devil(), err.Error())
+			return nil
+		}
+	}
+
+	notified := 0
+	for _, member := range names.members {
+		if only != "" && member.Name != only {
+			continue
+		}
+		value, ok, err := data(trip, member.Name)
+		if err != nil {
+			fmt.Printf("%s  %s\n", devil(), err.Error())
+			return nil
+		}
+		if !ok {
+			continue
+		}
+		if err := notify.Notify(kind, recipient(member), value); err != nil {
+			fmt.Printf("%s  Notifying %s failed: %s\n", devil(), member.Name, err.Error())
+			continue
+		}
+		notified++
+	}
+	if notified == 0 {
+		fmt.Printf("%s  Nobody is notified\n", devil())
+		return nil
+	}
+	fmt.Printf("%s  %d notified\n", celebrate(), notified)
+	return nil
+}
+
+// newNotifier delivers the notifications through the channels given with --channel
+func newNotifier(c *cli.Context) (*notifier.Notifier, error) {
+	templates, err := loadTemplates(c)
+	if err != nil {
+		return nil, err
+	}
+	notify := &notifier.Notifier{Templates: templates}
+	for _, name := range strings.Split(c.String("channel"), ",") {
+		switch strings.TrimSpace(name) {
+		case "smtp":
+			sender, from, err := newSender(c)
+			if err != nil {
+				return nil, err
+			}
+			notify.Channels = append(notify.Channels, &notifier.Mail{Sender: sender, From: from})
+		case "webhook":
+			if c.String("webhook") == "" {
+				return nil, errors.New("Please give the URL of the webhook")
+			}
+			notify.Channels = append(notify.Channels, &notifier.Webhook{URL: c.String("webhook")})
+		case "file":
+			notify.Channels = append(notify.Channels, &notifier.File{Path: c.String("file")})
+		case "stdout":
+			notify.Channels = append(notify.Channels, &notifier.Writer{Out: os.Stdout})
+		default:
+			return nil, fmt.Errorf("Unknown channel %s, use smtp, webhook, file or stdout", name)
+		}
+	}
+	return notify, nil
+}
+
+// tripSuggestion settles the trip and returns the balances it settles along with the currency the amounts are in
+func tripSuggestion(c *cli.Context, trip *database.Trip) (*splitter.PlanSuggestion, []splitter.Balance, string, error) {
+	members, shares, currency, err := tripShares(c, []*database.Trip{trip})
+	if err != nil {
+		return nil, nil, "", err
+	}
+	planSuggestion := splitter.CreateTotalSuggestion(trip.Id, 0, members, "", shares, splitter.WithCurrency(currency))
+	return planSuggestion, splitter.CreateBalances(trip.Id, members, shares), currency, nil
+}
+
+// formatSpent formats the amounts spent in each currency
+func formatSpent(spent map[string]money.Money) string {
+	currencies := make([]string, 0, len(spent))
+	for currency := range spent {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	amounts := make([]string, len(currencies))
+	for i, currency := range currencies {
+		amounts[i] = formatAmount(spent[currency], currency, "")
+	}
+	return strings.Join(amounts, ", ")
+}
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/urfave/cli"
+)
+
+func rateDateFlag(usage string) cli.Flag {
+	return cli.StringFlag{
+		Name:  "date",
+		Value: "",
+		Usage: usage,
+	}
+}
+
+//RatesCmd used to set/list/delete the exchange rates used for the transactions in other currencies
+func RatesCmd() cli.Command {
+	return cli.Command{
+		Name:  "rates",
+		Usage: "Manages the exchange rates used for the amounts in other currencies",
+		Subcommands: []cli.Command{
+			{
+				Name:      "set",
+				Usage:     "Sets the rate of the base currency in the quote currency eg. rates set EUR USD 1.08",
+				ArgsUsage: "<base> <quote> <rate>",
+				Flags:     []cli.Flag{rateDateFlag("Date the rate is effective from eg. 2026-01-31. Today if not given (Optional)")},
+				Action: func(c *cli.Context) error {
+					rate := money.Rate{
+						Base:  c.Args().Get(0),
+						Quote: c.Args().Get(1),
+						Value: c.Args().Get(2),
+						Date:  time.Now(),
+					}
+					if c.String("date") != "" {
+						var err error
+						if rate.Date, err = parseDate(c.String("date")); err != nil {
+							fmt.Printf("%s  %s\n", devil(), err.Error())
+							return nil
+						}
+					}
+					if err := database.SetRate(rate); err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "Lists the exchange rates",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "currency, c",
+						Value: "",
+						Usage: "Show the rates of the currency only (Optional)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					rates, err := database.ListRates()
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					currency := strings.ToUpper(strings.TrimSpace(c.String("currency")))
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "BASE\tQUOTE\tDATE\tRATE")
+					found := false
+					for _, rate := range rates {
+						if currency != "" && rate.Base != currency && rate.Quote != currency {
+							continue
+						}
+						found = true
+						fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rate.Base, rate.Quote, rate.Date.Format(dateLayout), rate.Value)
+					}
+					if !found {
+						fmt.Printf("%s  No rates found\n", devil())
+						return nil
+					}
+					w.Flush()
+					return nil
+				},
+			},
+			{
+				Name:      "import",
+				Usage:     "Imports the rates of an ECB eurofxref XML file or a CSV file with date, base, quote and rate in each row",
+				ArgsUsage: "<file>",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "format, f",
+						Value: "",
+						Usage: "ecb or csv. Files ending with .xml are read as ecb, the rest as csv if not given (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "from",
+						Value: "",
+						Usage: "Import the rates on or after the date eg. 2026-01-01 (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "currencies, c",
+						Value: "",
+						Usage: "Comma seperated currencies to import the rates of eg. USD, INR. Every currency if not given (Optional)",
+					},
+				},
+				Action: importRates,
+			},
+			{
+				Name:      "lookup",
+				Usage:     "Shows the rate used for converting the amounts on the date",
+				ArgsUsage: "<from> <to>",
+				Flags:     []cli.Flag{rateDateFlag("Date of the amounts eg. 2026-01-31. Today if not given (Optional)")},
+				Action: func(c *cli.Context) error {
+					date := time.Now()
+					if c.String("date") != "" {
+						var err error
+						if date, err = parseDate(c.String("date")); err != nil {
+							fmt.Printf("%s  %s\n", devil(), err.Error())
+							return nil
+						}
+					}
+					rates, err := database.LoadRates()
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					from, to := strings.ToUpper(c.Args().Get(0)), strings.ToUpper(c.Args().Get(1))
+					rate, err := rates.Lookup(from, to, date)
+					if err != nil {
+						fmt.Printf("%s  No exchange rate from %s to %s on %s\n", devil(), from, to, date.Format(dateLayout))
+						return nil
+					}
+					fmt.Printf("1 %s = %s %s\n", from, rate.FloatString(6), to)
+					return nil
+				},
+			},
+			{
+				Name:      "pivot",
+				Usage:     "Shows or sets the currency the missing rates are derived through eg. INR to USD through EUR",
+				ArgsUsage: "[currency]",
+				Action: func(c *cli.Context) error {
+					if c.Args().First() == "" {
+						pivot, err := database.RatePivot()
+						if err != nil {
+							fmt.Printf("%s  %s\n", devil(), err.Error())
+							return nil
+						}
+						fmt.Println(pivot)
+						return nil
+					}
+					if err := database.SetRatePivot(c.Args().First()); err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+			{
+				Name:      "delete",
+				Usage:     "Deletes the rate set on the date",
+				ArgsUsage: "<base> <quote>",
+				Flags:     []cli.Flag{rateDateFlag("Date the rate was set for eg. 2026-01-31 (Required)")},
+				Action: func(c *cli.Context) error {
+					date, err := parseDate(c.String("date"))
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					if err := database.DeleteRate(c.Args().Get(0), c.Args().Get(1), date); err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func importRates(c *cli.Context) error {
+	name := c.Args().First()
+	format := strings.ToLower(c.String("format"))
+	if format == "" {
+		format = "csv"
+		if strings.HasSuffix(strings.ToLower(name), ".xml") {
+			format = "ecb"
+		}
+	}
+	read, ok := map[string]func(io.Reader) ([]money.Rate, error){
+		"ecb": money.ReadECB,
+		"csv": money.ReadRatesCSV,
+	}[format]
+	if !ok {
+		fmt.Printf("%s  Format should be ecb or csv\n", devil())
+		return nil
+	}
+
+	var from time.Time
+	if c.String("from") != "" {
+		var err error
+		if from, err = parseDate(c.String("from")); err != nil {
+			fmt.Printf("%s  %s\n", devil(), err.Error())
+			return nil
+		}
+	}
+	currencies := make(map[string]bool)
+	if c.String("currencies") != "" {
+		for _, currency := range strings.Split(c.String("currencies"), ",") {
+			currencies[strings.ToUpper(strings.TrimSpace(currency))] = true
+		}
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	defer file.Close()
+	rates, err := read(file)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+
+	imported := make([]money.Rate, 0, len(rates))
+	for _, rate := range rates {
+		if rate.Date.Before(from) {
+			continue
+		}
+		if len(currencies) > 0 && !currencies[rate.Base] && !currencies[rate.Quote] {
+			continue
+		}
+		imported = append(imported, rate)
+	}
+	if len(imported) == 0 {
+		fmt.Printf("%s  No rates to import\n", devil())
+		return nil
+	}
+	if err := database.ImportRates(imported); err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	fmt.Printf("%s  imported %d rates\n", celebrate(), len(imported))
+	return nil
+}
+
+func currencyFlag(usage string) cli.Flag {
+	return cli.StringFlag{
+		Name:  "currency, c",
+		Value: "",
+		Usage: usage,
+	}
+}
+
+// currencyOf returns the currency of the amount, which is the currency of the trip unless the amount has its own
+func currencyOf(currency string, tripCurrency string) string {
+	if currency == "" {
+		return tripCurrency
+	}
+	return currency
+}
+
+// amountCurrency reads the currency given with --currency. It is empty when it is the currency of the trip.
+func amountCurrency(c *cli.Context, tripCurrency string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(c.String("currency")))
+	if currency == "" || currency == strings.ToUpper(tripCurrency) {
+		return "", nil
+	}
+	if tripCurrency == "" {
+		return "", fmt.Errorf("Trip has no currency to convert %s to, please create the trip with --currency", currency)
+	}
+	return currency, nil
+}
+
+// checkRate makes sure the amounts in the currency can be converted to the currency of the trip on the date
+func checkRate(currency string, tripCurrency string, date time.Time) error {
+	if currency == "" {
+		return nil
+	}
+	rates, err := database.LoadRates()
+	if err != nil {
+		return err
+	}
+	if _, err := rates.Lookup(currency, tripCurrency, date); err != nil {
+		return fmt.Errorf("No exchange rate from %s to %s on %s, please add it with rates set", currency, tripCurrency, date.Format(dateLayout))
+	}
+	return nil
+}
+
+// formatAmount formats the amount with its currency code when it is not the currency of the trip
+func formatAmount(amount money.Money, currency string, tripCurrency string) string {
+	currency = currencyOf(currency, tripCurrency)
+	if strings.EqualFold(currency, tripCurrency) {
+		return amount.Format(currency)
+	}
+	return amount.Format(currency) + " " + currency
+}
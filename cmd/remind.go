@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/mailer"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/notifier"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+func remindFlags() []cli.Flag {
+	return append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "member, m",
+			Value: "",
+			Usage: "Remind the member only. Everyone who owes is reminded if not given (Optional)",
+		},
+		currencyFlag("Currency to ask the amounts in, converted at the rates of the transaction dates (Optional)"),
+		tripFlag(),
+	}, mailFlags()...)
+}
+
+// mailFlags are the flags choosing how the reminders are delivered
+func mailFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Write the reminders as .eml files in --out instead of sending them",
+		},
+		cli.StringFlag{
+			Name:  "out, o",
+			Value: "reminders",
+			Usage: "Directory the reminders are written to with --dry-run (Optional)",
+		},
+		cli.StringFlag{
+			Name:   "smtp",
+			Value:  "localhost:25",
+			Usage:  "host:port of the SMTP server. Point it to a local stand-in like MailHog for testing (Optional)",
+			EnvVar: "EXPENSE_SMTP_ADDR",
+		},
+		cli.StringFlag{
+			Name:   "smtp-user",
+			Value:  "",
+			Usage:  "Username of the SMTP server. The server is used without authentication if not given (Optional)",
+			EnvVar: "EXPENSE_SMTP_USER",
+		},
+		cli.StringFlag{
+			Name:   "smtp-password",
+			Value:  "",
+			Usage:  "Password of the SMTP server (Optional)",
+			EnvVar: "EXPENSE_SMTP_PASSWORD",
+		},
+		cli.StringFlag{
+			Name:   "from",
+			Value:  "",
+			Usage:  "Address the reminders are sent from eg. Gus <gus@example.com> (Required unless --dry-run)",
+			EnvVar: "EXPENSE_MAIL_FROM",
+		},
+		templatesFlag(),
+	}
+}
+
+func templatesFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:   "templates",
+		Value:  "",
+		Usage:  "Directory of the templates overriding the built in messages eg. you_owe.subject.tmpl, you_owe.txt.tmpl, you_owe.html.tmpl. Name them eg. you_owe.es.txt.tmpl for the members reading another language (Optional)",
+		EnvVar: "EXPENSE_TEMPLATES",
+	}
+}
+
+//RemindCmd emails each member who owes what they have to pay and to whom
+func RemindCmd() cli.Command {
+	return cli.Command{
+		Name:        "remind",
+		Usage:       "Emails the members who owe money what they have to pay",
+		Flags:       remindFlags(),
+		Subcommands: remindSubcommands(),
+		Action: func(c *cli.Context) error {
+			sender, from, err := newSender(c)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			templates, err := loadTemplates(c)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+
+			trip, err := loadTrip(c)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			names, err := tripMemberNames(c, trip.Name)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			only := ""
+			if c.String("member") != "" {
+				if only, err = names.resolve(c.String("member")); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+			}
+
+			members, shares, currency, err := tripShares(c, []*database.Trip{trip})
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			planSuggestion := splitter.CreateTotalSuggestion(trip.Id, 0, members, "", shares, splitter.WithCurrency(currency))
+			debts := debtsByMember(planSuggestion.Suggestions)
+
+			for _, debtor := range debtorsInOrder(planSuggestion.Suggestions) {
+				if only != "" && debtor != only {
+					continue
+				}
+				member, _ := database.FindMember(names.members, debtor)
+				if member.Email == "" {
+					fmt.Printf("%s  %s has no email, add it with member edit %s --email\n", devil(), debtor, debtor)
+					continue
+				}
+
+				notification, err := templates.Render(notifier.YouOwe, recipient(member), oweData(trip, member, debts[debtor], currency))
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				message, err := (&notifier.Mail{Sender: sender, From: from}).Message(notification)
+				if err == nil {
+					err = sender.Send(message)
+				}
+				if err != nil {
+					fmt.Printf("%s  Reminding %s failed: %s\n", devil(), debtor, err.Error())
+					continue
+				}
+				if dir, ok := sender.(*mailer.Dir); ok {
+					fmt.Printf("%s  %s is written to %s\n", celebrate(), debtor, dir.File(message))
+				} else {
+					fmt.Printf("%s  %s is reminded at %s\n", celebrate(), debtor, member.Email)
+				}
+			}
+			if only != "" && len(debts[only]) == 0 {
+				fmt.Printf("%s  %s doesn't owe anything\n", celebrate(), only)
+			} else if len(debts) == 0 {
+				fmt.Printf("%s  Nobody owes anything\n", celebrate())
+			}
+			return nil
+		},
+	}
+}
+
+// newSender sends the reminders through the SMTP server or writes them to --out with --dry-run.
+// It returns the address the reminders are sent from too.
+func newSender(c *cli.Context) (mailer.Sender, string, error) {
+	from := c.String("from")
+	if c.Bool("dry-run") {
+		if from == "" {
+			from = "expensesplitter@localhost"
+		}
+		return &mailer.Dir{Path: c.String("out")}, from, nil
+	}
+	if from == "" {
+		return nil, "", errors.New("Please give the address the reminders are sent from")
+	}
+	return &mailer.SMTP{Addr: c.String("smtp"), Username: c.String("smtp-user"), Password: c.String("smtp-password")}, from, nil
+}
+
+// debtsByMember groups the suggestions by the member who has to pay
+func debtsByMember(suggestions []splitter.Suggestion) map[string][]splitter.Suggestion {
+	debts := make(map[string][]splitter.Suggestion)
+	for _, suggestion := range suggestions {
+		debts[suggestion.BMemberemail] = append(debts[suggestion.BMemberemail], suggestion)
+	}
+	return debts
+}
+
+// debtorsInOrder returns the members who have to pay in the order of the suggestions
+func debtorsInOrder(suggestions []splitter.Suggestion) []string {
+	debtors := make([]string, 0)
+	for _, suggestion := range suggestions {
+		debtors = addMember(debtors, suggestion.BMemberemail)
+	}
+	return debtors
+}
+
+// oweData is what the member has to pay to whom along with the transactions and settlements the member took part in
+func oweData(trip *database.Trip, member database.Member, debts []splitter.Suggestion, currency string) notifier.Owe {
+	data := notifier.Owe{Trip: trip.Name, Member: member.Name}
+	var total money.Money
+	for _, debt := range debts {
+		total = total + debt.Amount
+		data.Debts = append(data.Debts, notifier.Debt{To: debt.AMembername, Amount: formatAmount(debt.Amount, currency, "")})
+	}
+	data.Total = formatAmount(total, currency, "")
+
+	for _, transaction := range trip.Transactions {
+		if indexOfShare(transaction.Shares, member.Name) != -1 {
+			data.Items = append(data.Items, notifyItem(trip, transaction, member.Name))
+		}
+	}
+	for _, settlement := range trip.Settlements {
+		if settlement.From == member.Name || settlement.To == member.Name {
+			data.Payments = append(data.Payments, notifyPayment(trip, settlement))
+		}
+	}
+	return data
+}
+
+// notifyItem is the transaction as listed in the notifications of the member
+func notifyItem(trip *database.Trip, transaction database.Transaction, member string) notifier.Item {
+	item := notifier.Item{
+		Date:   transaction.Date,
+		Name:   transaction.Name,
+		Amount: formatAmount(transaction.Amount, transaction.Currency, trip.Currency),
+	}
+	if index := indexOfShare(transaction.Shares, member); index != -1 {
+		share := transaction.Shares[index]
+		item.Share = formatAmount(share.Amount, transaction.Currency, trip.Currency)
+		item.Paid = formatAmount(share.Paid, transaction.Currency, trip.Currency)
+	}
+	return item
+}
+
+// notifyPayment is the settlement as listed in the notifications
+func notifyPayment(trip *database.Trip, settlement database.Settlement) notifier.Payment {
+	return notifier.Payment{
+		Date:   settlement.Date,
+		From:   settlement.From,
+		To:     settlement.To,
+		Amount: formatAmount(settlement.Amount, settlement.Currency, trip.Currency),
+		Note:   settlement.Note,
+	}
+}
+
+func recipient(member database.Member) notifier.Recipient {
+	return notifier.Recipient{Name: member.Name, Email: member.Email, Language: member.Language}
+}
+
+// loadTemplates loads the templates of the notifications from --templates, the built in ones if not given
+func loadTemplates(c *cli.Context) (*notifier.Templates, error) {
+	if c.String("templates") == "" {
+		return notifier.DefaultTemplates(), nil
+	}
+	return notifier.LoadTemplates(c.String("templates"))
+}
@@ -0,0 +1,477 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/mailer"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/notifier"
+	"github.com/sankarvj/expensesplitter/pkg/reminder"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+const clockLayout = "2006-01-02 15:04"
+
+func remindSubcommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:  "run",
+			Usage: "Sends the reminders due as per the reminder policy. Safe to run from cron as often as needed",
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{
+					Name:  "all-trips, a",
+					Usage: "Runs the reminders of every trip which is not archived",
+				},
+				cli.StringFlag{
+					Name:  "now",
+					Value: "",
+					Usage: fmt.Sprintf("Time to run the reminders at eg. %s. Defaults to the current time (Optional)", clockLayout),
+				},
+				currencyFlag("Currency to ask the amounts in, converted at the rates of the transaction dates (Optional)"),
+				tripFlag(),
+			}, mailFlags()...),
+			Action: func(c *cli.Context) error {
+				now, err := parseNow(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				sender, from, err := newSender(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				templates, err := loadTemplates(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				trips, err := reminderTrips(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				for _, trip := range trips {
+					if err := runReminders(c, trip, sender, from, templates, now); err != nil {
+						fmt.Printf("%s  %s: %s\n", devil(), trip, err.Error())
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "Shows the reminders sent to each member who owes and when the next one is due",
+			Flags: []cli.Flag{
+				currencyFlag("Currency to show the amounts in, converted at the rates of the transaction dates (Optional)"),
+				tripFlag(),
+			},
+			Action: func(c *cli.Context) error {
+				trip, err := loadTrip(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				policy, err := database.ReminderPolicy(trip.Name)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				members, err := database.Members(trip.Name)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				log, err := database.Reminders(trip.Name)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				pending, currency, err := pendingReminders(c, trip, members, log, policy)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				fmt.Println(describePolicy(policy))
+				if len(pending) == 0 {
+					fmt.Printf("%s  Nobody owes anything\n", celebrate())
+					return nil
+				}
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "MEMBER\tEMAIL\tOWES\tREMINDERS\tLAST SENT\tNEXT")
+				for _, p := range pending {
+					last := "-"
+					if len(p.sent) > 0 {
+						last = p.sent[len(p.sent)-1].Sent.Format(clockLayout)
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", p.member.Name, p.member.Email, formatAmount(p.owed, currency, ""), len(p.sent), last, nextReminder(policy, p))
+				}
+				w.Flush()
+				return nil
+			},
+		},
+		{
+			Name:  "policy",
+			Usage: "Shows or changes the reminder policy of the trip",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "first",
+					Usage: "Days after the latest transaction of a member before reminding",
+				},
+				cli.IntFlag{
+					Name:  "every",
+					Usage: "Days between the reminders that follow",
+				},
+				cli.IntFlag{
+					Name:  "escalate",
+					Usage: "Reminders after which the members owed are copied in. 0 never copies them in",
+				},
+				cli.StringFlag{
+					Name:  "quiet",
+					Value: "",
+					Usage: "Hours no reminder is sent in eg. 22:00-07:00. off sends at any hour",
+				},
+				cli.BoolFlag{
+					Name:  "pause",
+					Usage: "Stops the scheduled reminders of the trip",
+				},
+				cli.BoolFlag{
+					Name:  "resume",
+					Usage: "Resumes the scheduled reminders of the trip",
+				},
+				tripFlag(),
+			},
+			Action: func(c *cli.Context) error {
+				trip, err := tripName(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				policy, err := database.ReminderPolicy(trip)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				if c.NumFlags() == 0 || (c.NumFlags() == 1 && c.IsSet("trip")) {
+					fmt.Println(describePolicy(policy))
+					return nil
+				}
+
+				if c.IsSet("first") {
+					policy.First = c.Int("first")
+				}
+				if c.IsSet("every") {
+					policy.Every = c.Int("every")
+				}
+				if c.IsSet("escalate") {
+					policy.Escalate = c.Int("escalate")
+				}
+				if c.IsSet("quiet") {
+					if policy.QuietFrom, policy.QuietTo, err = parseQuietHours(c.String("quiet")); err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+				}
+				if c.Bool("pause") {
+					policy.Paused = true
+				}
+				if c.Bool("resume") {
+					policy.Paused = false
+				}
+				if err := database.SetReminderPolicy(trip, policy); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				fmt.Println(describePolicy(policy))
+				fmt.Printf("%s  success\n", celebrate())
+				return nil
+			},
+		},
+		{
+			Name:      "optout",
+			Usage:     "Stops the scheduled reminders of the member",
+			ArgsUsage: "<name>",
+			Flags:     []cli.Flag{tripFlag()},
+			Action: func(c *cli.Context) error {
+				return optOut(c, true)
+			},
+		},
+		{
+			Name:      "optin",
+			Usage:     "Resumes the scheduled reminders of the member",
+			ArgsUsage: "<name>",
+			Flags:     []cli.Flag{tripFlag()},
+			Action: func(c *cli.Context) error {
+				return optOut(c, false)
+			},
+		},
+	}
+}
+
+// pendingReminder is the state of the scheduled reminders of a member who owes money
+type pendingReminder struct {
+	member database.Member
+	debts  []splitter.Suggestion
+	owed   money.Money
+	since  time.Time           // date of the latest transaction of the member
+	sent   []database.Reminder // reminders sent since the member was last settled
+}
+
+// pendingReminders works out the reminders of the members of the trip who owe money along with the currency of the amounts
+func pendingReminders(c *cli.Context, trip *database.Trip, members []database.Member, log []database.Reminder, policy reminder.Policy) ([]pendingReminder, string, error) {
+	tripMembers, shares, currency, err := tripShares(c, []*database.Trip{trip})
+	if err != nil {
+		return nil, "", err
+	}
+	planSuggestion := splitter.CreateTotalSuggestion(trip.Id, 0, tripMembers, "", shares, splitter.WithCurrency(currency))
+	debts := debtsByMember(planSuggestion.Suggestions)
+
+	pending := make([]pendingReminder, 0)
+	for _, debtor := range debtorsInOrder(planSuggestion.Suggestions) {
+		member, ok := database.FindMember(members, debtor)
+		if !ok {
+			member = database.Member{Name: debtor}
+		}
+		p := pendingReminder{member: member, debts: debts[debtor], sent: database.RemindersSince(log, debtor)}
+		for _, debt := range p.debts {
+			p.owed = p.owed + debt.Amount
+		}
+		for _, transaction := range trip.Transactions {
+			if indexOfShare(transaction.Shares, debtor) != -1 && transaction.Date.After(p.since) {
+				p.since = transaction.Date
+			}
+		}
+		pending = append(pending, p)
+	}
+	return pending, currency, nil
+}
+
+// lastSent is the time of the last reminder sent to the member, zero if none
+func (p pendingReminder) lastSent() time.Time {
+	if len(p.sent) == 0 {
+		return time.Time{}
+	}
+	return p.sent[len(p.sent)-1].Sent
+}
+
+// runReminders sends the reminders of the trip due at now and logs them.
+// Members who paid up since their last reminder are logged as settled so that their next reminders start over.
+func runReminders(c *cli.Context, tripName string, sender mailer.Sender, from string, templates *notifier.Templates, now time.Time) error {
+	dryRun := c.Bool("dry-run")
+	policy, err := database.ReminderPolicy(tripName)
+	if err != nil {
+		return err
+	}
+	if policy.Paused {
+		fmt.Printf("%s  Reminders of %s are paused\n", devil(), tripName)
+		return nil
+	}
+	trip, err := database.LoadTrip(tripName)
+	if err != nil {
+		return err
+	}
+	members, err := database.Members(tripName)
+	if err != nil {
+		return err
+	}
+	log, err := database.Reminders(tripName)
+	if err != nil {
+		return err
+	}
+	pending, currency, err := pendingReminders(c, trip, members, log, policy)
+	if err != nil {
+		return err
+	}
+
+	owing := make(map[string]bool)
+	for _, p := range pending {
+		owing[p.member.Name] = true
+	}
+	for _, member := range members {
+		if owing[member.Name] || len(database.RemindersSince(log, member.Name)) == 0 || dryRun {
+			continue
+		}
+		if err := database.LogReminder(tripName, database.Reminder{Member: member.Name, Sent: now, Settled: true}); err != nil {
+			return err
+		}
+	}
+
+	sent := 0
+	for _, p := range pending {
+		if p.member.OptOut || !policy.Due(now, p.since, p.lastSent()) {
+			continue
+		}
+		if p.member.Email == "" {
+			fmt.Printf("%s  %s: %s has no email, add it with member edit %s --email\n", devil(), tripName, p.member.Name, p.member.Name)
+			continue
+		}
+
+		data := oweData(trip, p.member, p.debts, currency)
+		var cc []notifier.Recipient
+		if policy.Escalated(len(p.sent)) {
+			cc = escalate(&data, p, members)
+		}
+		notification, err := templates.Render(notifier.YouOwe, recipient(p.member), data)
+		if err != nil {
+			return err
+		}
+		notification.Cc = cc
+		notification.Date = now
+		message, err := (&notifier.Mail{Sender: sender, From: from}).Message(notification)
+		if err == nil {
+			err = sender.Send(message)
+		}
+		if err != nil {
+			fmt.Printf("%s  %s: reminding %s failed: %s\n", devil(), tripName, p.member.Name, err.Error())
+			continue
+		}
+		sent++
+		if dir, ok := sender.(*mailer.Dir); ok {
+			fmt.Printf("%s  %s: %s is written to %s\n", celebrate(), tripName, p.member.Name, dir.File(message))
+			continue
+		}
+		// logged right after sending so that a failing run doesn't send it twice when run again
+		entry := database.Reminder{Member: p.member.Name, Email: p.member.Email, Amount: p.owed, Currency: currency, Sent: now, Escalated: data.Escalated}
+		if err := database.LogReminder(tripName, entry); err != nil {
+			return err
+		}
+		fmt.Printf("%s  %s: %s is reminded at %s\n", celebrate(), tripName, p.member.Name, p.member.Email)
+	}
+	if sent == 0 {
+		fmt.Printf("%s  %s: no reminders are due\n", celebrate(), tripName)
+	}
+	return nil
+}
+
+// escalate marks the reminder overdue and returns the members owed to copy in
+func escalate(data *notifier.Owe, p pendingReminder, members []database.Member) []notifier.Recipient {
+	data.Escalated = true
+	data.Reminder = len(p.sent) + 1
+	cc := make([]notifier.Recipient, 0)
+	for _, debt := range p.debts {
+		creditor, ok := database.FindMember(members, debt.AMemberemail)
+		if !ok || creditor.Email == "" {
+			continue
+		}
+		cc = append(cc, recipient(creditor))
+		data.Copied = append(data.Copied, creditor.Name)
+	}
+	return cc
+}
+
+// nextReminder tells when the member is reminded next as per the policy
+func nextReminder(policy reminder.Policy, p pendingReminder) string {
+	switch {
+	case policy.Paused:
+		return "paused"
+	case p.member.OptOut:
+		return "opted out"
+	case p.member.Email == "":
+		return "no email"
+	}
+	next := policy.Next(p.since, p.lastSent()).Format(clockLayout)
+	if policy.Escalated(len(p.sent)) {
+		next = next + " (escalated)"
+	}
+	return next
+}
+
+// describePolicy explains the policy in a sentence
+func describePolicy(policy reminder.Policy) string {
+	description := fmt.Sprintf("Reminding %d days after the latest transaction, then every %d days", policy.First, policy.Every)
+	if policy.Escalate > 0 {
+		description = description + fmt.Sprintf(", copying the members owed in from reminder %d", policy.Escalate+1)
+	}
+	if policy.QuietFrom != "" {
+		description = description + fmt.Sprintf(", quiet from %s to %s", policy.QuietFrom, policy.QuietTo)
+	}
+	if policy.Paused {
+		description = description + " (paused)"
+	}
+	return description
+}
+
+// reminderTrips returns the trip given with --trip or the current trip, or every trip not archived with --all-trips
+func reminderTrips(c *cli.Context) ([]string, error) {
+	if !c.Bool("all-trips") {
+		trip, err := tripName(c)
+		if err != nil {
+			return nil, err
+		}
+		return []string{trip}, nil
+	}
+	infos, err := database.Trips()
+	if err != nil {
+		return nil, err
+	}
+	trips := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if !info.Archived {
+			trips = append(trips, info.Name)
+		}
+	}
+	return trips, nil
+}
+
+// parseNow parses --now, the current time if it is not given
+func parseNow(c *cli.Context) (time.Time, error) {
+	if c.String("now") == "" {
+		return time.Now(), nil
+	}
+	now, err := time.ParseInLocation(clockLayout, c.String("now"), time.Local)
+	if err != nil {
+		return now, fmt.Errorf("Please enter valid time eg. %s", clockLayout)
+	}
+	return now, nil
+}
+
+// parseQuietHours parses the quiet hours given as 22:00-07:00, off clears them
+func parseQuietHours(value string) (string, string, error) {
+	if value == "off" {
+		return "", "", nil
+	}
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return "", "", reminder.ErrInvalidPolicy
+	}
+	from, to := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if _, err := reminder.ParseClock(from); err != nil {
+		return "", "", err
+	}
+	if _, err := reminder.ParseClock(to); err != nil {
+		return "", "", err
+	}
+	return from, to, nil
+}
+
+// optOut stops or resumes the scheduled reminders of the member named in the arguments
+func optOut(c *cli.Context, optOut bool) error {
+	trip, err := tripName(c)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	names, err := tripMemberNames(c, trip)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	name, err := names.resolve(c.Args().First())
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	if err := database.OptOutMember(trip, name, optOut); err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	fmt.Printf("%s  success\n", celebrate())
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sankarvj/expensesplitter/server"
+	"github.com/urfave/cli"
+)
+
+func serveFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:   "addr",
+			Value:  ":8080",
+			Usage:  "host:port the API listens on. Use localhost:8080 to keep it off the LAN (Optional)",
+			EnvVar: "EXPENSE_ADDR",
+		},
+		cli.StringFlag{
+			Name:   "token",
+			Value:  "",
+			Usage:  "Token the requests should carry as Authorization: Bearer <token>. Anyone on the network can change the trips if not given (Optional)",
+			EnvVar: "EXPENSE_API_TOKEN",
+		},
+	}
+}
+
+//ServeCmd serves the trips as a JSON REST API, so the expenses can be added from other devices
+func ServeCmd() cli.Command {
+	return cli.Command{
+		Name:  "serve",
+		Usage: "Serves the trips as a JSON REST API eg. GET /trips/{id}/suggestions",
+		Flags: serveFlags(),
+		Action: func(c *cli.Context) error {
+			if c.String("token") == "" {
+				fmt.Printf("%s  No --token given, anyone reaching %s can change the trips\n", devil(), c.String("addr"))
+			}
+			fmt.Printf("%s  Serving the API on %s\n", celebrate(), c.String("addr"))
+			if err := http.ListenAndServe(c.String("addr"), server.New(c.String("token"))); err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+			}
+			return nil
+		},
+	}
+}
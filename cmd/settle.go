@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+func settleFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "from, f",
+			Value: "",
+			Usage: "Member who paid eg. jesse (Required)",
+		},
+		cli.StringFlag{
+			Name:  "to",
+			Value: "",
+			Usage: "Member who got paid eg. walt (Required)",
+		},
+		cli.StringFlag{
+			Name:  "amount, a",
+			Value: "",
+			Usage: "Amount paid. Whatever the suggestion says the member owes is settled if not given (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "date",
+			Value: "",
+			Usage: "Date of the payment eg. 2026-01-31. Today if not given (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "note, n",
+			Value: "",
+			Usage: "Note about the payment (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "ref",
+			Value: "",
+			Usage: "Reference of the payment eg. bank transfer id (Optional)",
+		},
+		currencyFlag("Currency the payment is made in. Trip's currency if not given (Optional)"),
+		tripFlag(),
+	}
+}
+
+//SettleCmd records the repayments made between the members
+func SettleCmd() cli.Command {
+	return cli.Command{
+		Name:  "settle",
+		Usage: "Records a repayment made by a member to another",
+		Flags: settleFlags(),
+		Subcommands: []cli.Command{
+			{
+				Name:   "list",
+				Usage:  "Lists the settlements of the trip",
+				Flags:  []cli.Flag{tripFlag()},
+				Action: listSettlements,
+			},
+			{
+				Name:      "delete",
+				Usage:     "Deletes the settlement",
+				ArgsUsage: "<id>",
+				Flags:     []cli.Flag{tripFlag()},
+				Action:    deleteSettlement,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if strings.TrimSpace(c.String("from")) == "" || strings.TrimSpace(c.String("to")) == "" {
+				fmt.Printf("%s  Please give the member who paid and the member who got paid\n", devil())
+				return nil
+			}
+
+			info, err := tripInfo(c)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			names, err := tripMemberNames(c, info.Name)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			from, err := names.resolve(c.String("from"))
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			to, err := names.resolve(c.String("to"))
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+
+			settlement := database.Settlement{
+				From:      from,
+				To:        to,
+				Note:      c.String("note"),
+				Reference: c.String("ref"),
+			}
+			if c.String("date") != "" {
+				if settlement.Date, err = parseDate(c.String("date")); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+			}
+
+			if settlement.Currency, err = amountCurrency(c, info.Currency); err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			currency := currencyOf(settlement.Currency, info.Currency)
+			date := settlement.Date
+			if date.IsZero() {
+				date = time.Now()
+			}
+			if err := checkRate(settlement.Currency, info.Currency, date); err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+
+			if c.String("amount") != "" {
+				if settlement.Amount, err = money.Parse(c.String("amount"), currency); err != nil {
+					fmt.Printf("%s  Please enter valid amount\n", devil())
+					return nil
+				}
+			} else if settlement.Amount, err = owedAmount(c, info, from, to); err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+
+			id, err := database.AddSettlement(info.Name, settlement)
+			if err != nil {
+				fmt.Printf("%s  %s\n", devil(), err.Error())
+				return nil
+			}
+			fmt.Printf("%s  %s paid %s %s (id %d)\n", celebrate(), from, to, formatAmount(settlement.Amount, settlement.Currency, info.Currency), id)
+			return nil
+		},
+	}
+}
+
+// owedAmount is the amount the suggestion asks the member to pay the other member in the currency given with --currency
+func owedAmount(c *cli.Context, info *database.TripInfo, from, to string) (money.Money, error) {
+	trip, err := database.LoadTrip(info.Name)
+	if err != nil {
+		return 0, err
+	}
+	members, shares, currency, err := tripShares(c, []*database.Trip{trip})
+	if err != nil {
+		return 0, err
+	}
+	planSuggestion := splitter.CreateTotalSuggestion(trip.Id, 0, members, from, shares, splitter.WithCurrency(currency))
+	for _, suggestion := range planSuggestion.Suggestions {
+		if suggestion.BMemberemail == from && suggestion.AMemberemail == to {
+			return suggestion.Amount, nil
+		}
+	}
+	return 0, fmt.Errorf("%s doesn't owe %s anything, please give the amount", from, to)
+}
+
+func listSettlements(c *cli.Context) error {
+	info, err := tripInfo(c)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	settlements, err := database.Settlements(info.Name)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	if len(settlements) == 0 {
+		fmt.Printf("%s  No settlements found\n", devil())
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDATE\tFROM\tTO\tAMOUNT\tNOTE\tREF")
+	for _, settlement := range settlements {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", settlement.Id, settlement.Date.Format(dateLayout), settlement.From, settlement.To, formatAmount(settlement.Amount, settlement.Currency, info.Currency), orDash(settlement.Note), orDash(settlement.Reference))
+	}
+	w.Flush()
+	return nil
+}
+
+func deleteSettlement(c *cli.Context) error {
+	info, err := tripInfo(c)
+	if err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	id, err := strconv.ParseInt(c.Args().First(), 10, 64)
+	if err != nil {
+		fmt.Printf("%s  Please give the settlement id\n", devil())
+		return nil
+	}
+
+	_, yes := waitforinput(fmt.Sprintf("Do you really want to delete the settlement %d? (yes/no)", id))
+	if !yes {
+		return nil
+	}
+	if err := database.DeleteSettlement(info.Name, id); err != nil {
+		fmt.Printf("%s  %s\n", devil(), err.Error())
+		return nil
+	}
+	fmt.Printf("%s  success\n", celebrate())
+	return nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+func splitFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:  "split",
+		Value: "",
+		Usage: "How the shares are read: equal, exact, percent (eg. 50, 30, 20), weight (eg. 2, 3, 3) or adjust (eg. 0, +10, -10 over the equal split). Items split is given with --item. Defaults to exact if shares given otherwise equal (Optional)",
+	}
+}
+
+// parseWeights reads the comma seperated shares as the weights of the split mode
+func parseWeights(mode splitter.SplitMode, shares []string, currency string) ([]int64, error) {
+	weights := make([]int64, len(shares))
+	for i, share := range shares {
+		share = strings.TrimSpace(share)
+		switch mode {
+		case splitter.SplitPercent:
+			percent, err := money.Parse(strings.TrimSuffix(share, "%"), "")
+			if err != nil {
+				return nil, fmt.Errorf("Please enter valid percentage eg. 33.33")
+			}
+			weights[i] = percent.Minor() // hundredths of a percent are basis points
+		case splitter.SplitWeight:
+			weight, err := strconv.ParseInt(share, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Please enter valid weight eg. 2")
+			}
+			weights[i] = weight
+		default:
+			amount, err := money.Parse(share, currency)
+			if err != nil {
+				return nil, fmt.Errorf("Please enter share amount")
+			}
+			weights[i] = amount.Minor()
+		}
+	}
+	return weights, nil
+}
+
+// resolveShares resolves the shares at the indexes into their amounts as per the split mode.
+// The equal split only marks the shares auto, splitAutoShares computes them later.
+func resolveShares(transaction *database.Transaction, indexes []int, mode splitter.SplitMode, weights []int64, remainder splitter.Remainder) error {
+	splitterShares := make([]splitter.Share, len(indexes))
+	for i, index := range indexes {
+		share := transaction.Shares[index]
+		splitterShares[i] = splitter.Share{
+			Memberemail:     share.Member,
+			Membername:      share.Member,
+			Benefactoremail: share.Member,
+			Paid:            share.Paid,
+		}
+	}
+
+	resolved, err := splitter.SplitBy(mode, transaction.Amount, splitterShares, weights, remainder)
+	if err != nil {
+		return err
+	}
+	for i, index := range indexes {
+		share := &transaction.Shares[index]
+		share.Auto = resolved[i].Auto
+		share.Weight = 0
+		if !share.Auto {
+			share.Amount = resolved[i].Share
+		}
+		if isWeighted(mode) {
+			share.Weight = weights[i]
+		}
+	}
+	transaction.Split = mode.String()
+	return nil
+}
+
+// transactionSplitMode returns the split mode the transaction was added with.
+// Transactions stored before the split mode was recorded keep their shares as they are.
+func transactionSplitMode(transaction database.Transaction) splitter.SplitMode {
+	if strings.TrimSpace(transaction.Split) == "" {
+		return splitter.SplitExact
+	}
+	mode, err := splitter.ParseSplitMode(transaction.Split)
+	if err != nil {
+		return splitter.SplitExact
+	}
+	return mode
+}
+
+// isWeighted tells whether the shares of the mode are derived from the weights and the amount
+func isWeighted(mode splitter.SplitMode) bool {
+	return mode == splitter.SplitPercent || mode == splitter.SplitWeight || mode == splitter.SplitAdjust
+}
+
+func formatWeight(share database.Share, mode splitter.SplitMode, currency string) string {
+	switch mode {
+	case splitter.SplitPercent:
+		return money.FromMinor(share.Weight).String() + "%"
+	case splitter.SplitWeight:
+		return strconv.FormatInt(share.Weight, 10)
+	case splitter.SplitAdjust:
+		if share.Weight >= 0 {
+			return "+" + money.FromMinor(share.Weight).Format(currency)
+		}
+		return money.FromMinor(share.Weight).Format(currency)
+	}
+	return ""
+}
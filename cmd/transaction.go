@@ -0,0 +1,671 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+const dateLayout = "2006-01-02"
+
+func listFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "from",
+			Value: "",
+			Usage: "Show transactions on or after the date eg. 2026-01-31 (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "to",
+			Value: "",
+			Usage: "Show transactions on or before the date eg. 2026-02-28 (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "member, m",
+			Value: "",
+			Usage: "Show transactions shared by the member (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "name, n",
+			Value: "",
+			Usage: "Show transactions whose name contains the text (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "min",
+			Value: "",
+			Usage: "Show transactions with amount atleast this value in the trip's currency. Amounts in other currencies are converted at the rate of their date, those without a rate are left out (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "max",
+			Value: "",
+			Usage: "Show transactions with amount atmost this value in the trip's currency, converted like --min (Optional)",
+		},
+		tripFlag(),
+	}
+}
+
+func transactionSubcommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:  "list",
+			Usage: "Lists the transactions of the trip",
+			Flags: listFlags(),
+			Action: func(c *cli.Context) error {
+				info, err := tripInfo(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				filter, err := parseFilter(c, info)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				trip, err := database.LoadTrip(info.Name)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				transactions := trip.Filter(filter)
+				if len(transactions) == 0 {
+					fmt.Printf("%s  No transactions found\n", devil())
+					return nil
+				}
+				printTransactions(transactions, info.Currency)
+				return nil
+			},
+		},
+		{
+			Name:      "show",
+			Usage:     "Shows the full transaction",
+			ArgsUsage: "<id>",
+			Flags:     []cli.Flag{tripFlag()},
+			Action: func(c *cli.Context) error {
+				info, err := tripInfo(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				id, err := transactionID(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				transaction, err := database.FindTransaction(info.Name, id)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				printTransaction(transaction, info.Currency)
+				return nil
+			},
+		},
+		{
+			Name:      "edit",
+			Usage:     "Edits the transaction",
+			ArgsUsage: "<id>",
+			Flags:     editFlags(),
+			Action: func(c *cli.Context) error {
+				info, err := tripInfo(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				id, err := transactionID(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				transaction, err := database.FindTransaction(info.Name, id)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				names, err := tripMemberNames(c, info.Name)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				currency := currencyOf(transaction.Currency, info.Currency)
+				updated, err := editTransaction(c, names, info.Name, *transaction, currency)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				if err := names.checkActive(sharingNames(updated.Shares), updated.Date); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				if err := checkRate(updated.Currency, info.Currency, updated.Date); err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				members, shares, paid := shareSlices(updated.Shares)
+				if ok, reason := validateShares(members, shares, paid, updated.Amount); !ok {
+					fmt.Printf("%s  %s\n", devil(), reason)
+					return nil
+				}
+
+				err = database.UpdateTransaction(info.Name, id, updated)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				printTransaction(&updated, info.Currency)
+				reportRemainder(updated.Shares, currency)
+				fmt.Printf("%s  success\n", celebrate())
+				return nil
+			},
+		},
+		{
+			Name:      "delete",
+			Usage:     "Deletes the transaction",
+			ArgsUsage: "<id>",
+			Flags:     []cli.Flag{tripFlag()},
+			Action: func(c *cli.Context) error {
+				info, err := tripInfo(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				id, err := transactionID(c)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				transaction, err := database.FindTransaction(info.Name, id)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+
+				printTransaction(transaction, info.Currency)
+				_, yes := waitforinput(fmt.Sprintf("Do you really want to delete %s? (yes/no)", transaction.Name))
+				if !yes {
+					return nil
+				}
+
+				err = database.DeleteTransaction(info.Name, id)
+				if err != nil {
+					fmt.Printf("%s  %s\n", devil(), err.Error())
+					return nil
+				}
+				fmt.Printf("%s  success\n", celebrate())
+				return nil
+			},
+		},
+	}
+}
+
+// parseDate reads the date in the local time
+func parseDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation(dateLayout, strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return date, fmt.Errorf("Please enter valid date eg. %s", dateLayout)
+	}
+	return date, nil
+}
+
+// formatDay formats the date, a dash if it is not set
+func formatDay(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return date.Format(dateLayout)
+}
+
+func transactionID(c *cli.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Args().First(), 10, 64)
+	if err != nil {
+		return 0, errors.New("Please give the transaction id")
+	}
+	return id, nil
+}
+
+func shareSlices(shares []database.Share) ([]string, []money.Money, []money.Money) {
+	members := make([]string, len(shares))
+	amounts := make([]money.Money, len(shares))
+	paid := make([]money.Money, len(shares))
+	for i, share := range shares {
+		members[i] = share.Member
+		amounts[i] = share.Amount
+		paid[i] = share.Paid
+	}
+	return members, amounts, paid
+}
+
+func editFlags() []cli.Flag {
+	return append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "name, n",
+			Value: "",
+			Usage: "New name of the transaction (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "members, m",
+			Value: "",
+			Usage: "Comma seperated names who share the transaction eg. gus, walt, jesse etc. (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "share, s",
+			Value: "",
+			Usage: "Comma seperated shares in the same order of members. Read as per --split or the split of the transaction (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "expense, e",
+			Value: "",
+			Usage: "New amount of the transaction (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "paid-by, p",
+			Value: "",
+			Usage: "Member who paid the bill eg. gus or comma seperated payers with their amount eg. gus:60, walt:40 (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "date",
+			Value: "",
+			Usage: "New date of the transaction eg. 2026-01-31 (Optional)",
+		},
+		cli.StringFlag{
+			Name:  "remainder, r",
+			Value: "",
+			Usage: "Who carries the remainder of the equal split: first, payer, round-robin or random. Trip's policy is used if not given (Optional)",
+		},
+		cli.Int64Flag{
+			Name:  "seed",
+			Usage: "Seed for the random remainder policy (Optional)",
+		},
+		splitFlag(),
+		createMembersFlag(),
+		tripFlag(),
+	}, itemFlags()...)
+}
+
+// editTransaction applies the changes given in the flags and recomputes the shares which are split equally
+func editTransaction(c *cli.Context, names *memberNames, tripName string, transaction database.Transaction, currency string) (database.Transaction, error) {
+	updated := transaction
+	updated.Shares = append([]database.Share{}, transaction.Shares...)
+
+	if c.String("name") != "" {
+		updated.Name = c.String("name")
+	}
+
+	if c.String("date") != "" {
+		date, err := parseDate(c.String("date"))
+		if err != nil {
+			return updated, err
+		}
+		old := transaction.Date.In(time.Local)
+		updated.Date = time.Date(date.Year(), date.Month(), date.Day(), old.Hour(), old.Minute(), old.Second(), old.Nanosecond(), time.Local)
+	}
+
+	if isItemised(c) || transactionSplitMode(transaction) == splitter.SplitItemised {
+		return editItems(c, names, tripName, updated, currency)
+	}
+
+	amountChanged := false
+	if c.String("expense") != "" {
+		amount, err := money.Parse(c.String("expense"), currency)
+		if err != nil {
+			return updated, errors.New("Please enter valid expense")
+		}
+		amountChanged = amount != transaction.Amount
+		updated.Amount = amount
+	}
+
+	if c.String("members") != "" {
+		members, err := names.resolveAll(strings.Split(c.String("members"), ","))
+		if err != nil {
+			return updated, err
+		}
+		updated.Shares = replaceMembers(updated.Shares, members)
+	}
+
+	mode := transactionSplitMode(transaction)
+	if c.String("split") != "" {
+		var err error
+		if mode, err = splitter.ParseSplitMode(c.String("split")); err != nil {
+			return updated, err
+		}
+	} else if mode == splitter.SplitEqual && c.String("share") != "" {
+		mode = splitter.SplitExact // shares given for an equally split transaction are the amounts
+	}
+
+	sharing := sharingMembers(updated.Shares)
+	resolve := false
+	var weights []int64
+	if c.String("share") != "" {
+		shares := strings.Split(c.String("share"), ",")
+		if len(shares) != len(sharing) {
+			return updated, errors.New("Given members and their shares are not matching")
+		}
+		if mode == splitter.SplitEqual {
+			return updated, errors.New("Shares are not needed for the equal split")
+		}
+		var err error
+		if weights, err = parseWeights(mode, shares, currency); err != nil {
+			return updated, err
+		}
+		if mode == splitter.SplitExact && c.String("expense") == "" {
+			var total money.Money
+			for _, weight := range weights {
+				total = total + money.FromMinor(weight)
+			}
+			amountChanged = total != transaction.Amount
+			updated.Amount = total
+		}
+		resolve = true
+	} else if c.String("split") != "" {
+		if mode != splitter.SplitEqual {
+			return updated, fmt.Errorf("Please give the shares for the %s split", mode)
+		}
+		resolve = true
+	} else if isWeighted(mode) && c.String("members") != "" {
+		return updated, fmt.Errorf("Please give the shares of the members for the %s split", mode)
+	} else if isWeighted(mode) && amountChanged {
+		// the shares follow the new amount with the same weights
+		weights = make([]int64, len(sharing))
+		for i, index := range sharing {
+			weights[i] = updated.Shares[index].Weight
+		}
+		resolve = true
+	}
+
+	if c.String("paid-by") != "" {
+		var err error
+		if updated.Shares, err = applyPaidBy(names, updated.Shares, c.String("paid-by"), updated.Amount, currency); err != nil {
+			return updated, err
+		}
+	} else if payers := payerIndexes(updated.Shares); amountChanged && len(payers) == 1 {
+		// the only payer paid the new amount too
+		updated.Shares[payers[0]].Paid = updated.Amount
+	}
+
+	remainder, err := parseRemainder(c, tripName, transaction.Id)
+	if err != nil {
+		return updated, err
+	}
+	if resolve {
+		if err := resolveShares(&updated, sharing, mode, weights, remainder); err != nil {
+			return updated, err
+		}
+	}
+	updated.Shares = splitAutoShares(updated.Shares, updated.Amount, remainder)
+	return updated, nil
+}
+
+// replaceMembers keeps the shares of the members given, adds the new members to the equal split
+// and drops the rest unless they paid for the transaction.
+func replaceMembers(shares []database.Share, members []string) []database.Share {
+	replaced := make([]database.Share, 0)
+	for _, member := range members {
+		share := database.Share{Member: member, Auto: true}
+		for _, existing := range shares {
+			if strings.TrimSpace(existing.Member) == strings.TrimSpace(member) {
+				share = existing
+				share.Auto = existing.Auto || existing.Amount == 0
+				break
+			}
+		}
+		replaced = append(replaced, share)
+	}
+
+	for _, existing := range shares {
+		if existing.Paid != 0 && indexOfMember(members, existing.Member) == -1 {
+			existing.Amount = 0
+			existing.Auto = false
+			replaced = append(replaced, existing)
+		}
+	}
+	return replaced
+}
+
+// sharingMembers returns the index of the shares which take part in the split
+func sharingMembers(shares []database.Share) []int {
+	indexes := make([]int, 0)
+	for i, share := range shares {
+		if share.Auto || share.Amount != 0 || share.Weight != 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// sharingNames returns the names of the members who take part in the split
+func sharingNames(shares []database.Share) []string {
+	indexes := sharingMembers(shares)
+	names := make([]string, len(indexes))
+	for i, index := range indexes {
+		names[i] = shares[index].Member
+	}
+	return names
+}
+
+func payerIndexes(shares []database.Share) []int {
+	indexes := make([]int, 0)
+	for i, share := range shares {
+		if share.Paid != 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// splitAutoShares recomputes the shares which are split equally using the splitter.
+// The manual shares are left untouched and the rest of the bill is split among the auto shares.
+func splitAutoShares(shares []database.Share, billAmount money.Money, remainder splitter.Remainder) []database.Share {
+	members := make([]splitter.Member, len(shares))
+	splitterShares := make([]splitter.Share, len(shares))
+	for i, share := range shares {
+		members[i] = splitter.Member{
+			Name:  share.Member,
+			Email: share.Member,
+		}
+		splitterShares[i] = splitter.Share{
+			Memberemail:     share.Member,
+			Membername:      share.Member,
+			Benefactoremail: share.Member,
+			Share:           share.Amount,
+			Paid:            share.Paid,
+			Auto:            share.Auto,
+		}
+	}
+
+	splitShares, _, _ := splitter.SplitSharesForBill(0, 0, billAmount, "", members, splitterShares, splitter.WithRemainder(remainder))
+	for _, splitShare := range splitShares {
+		for i := range shares {
+			if shares[i].Member == splitShare.Memberemail && shares[i].Auto {
+				shares[i].Amount = splitShare.Share
+			}
+		}
+	}
+	return shares
+}
+
+// parseRemainder returns the remainder policy given with --remainder or the one of the trip.
+// The position of the transaction in the trip is used by round robin; id -1 is a new transaction.
+func parseRemainder(c *cli.Context, tripName string, id int64) (splitter.Remainder, error) {
+	remainder := splitter.Remainder{}
+	name := c.String("remainder")
+	if name == "" {
+		info, err := database.GetTrip(tripName)
+		if err != nil {
+			return remainder, err
+		}
+		name = info.Remainder
+	}
+
+	policy, err := splitter.ParseRemainderPolicy(name)
+	if err != nil {
+		return remainder, err
+	}
+	remainder.Policy = policy
+
+	if policy == splitter.RemainderRoundRobin || policy == splitter.RemainderRandom {
+		trip, err := database.LoadTrip(tripName)
+		if err != nil {
+			return remainder, err
+		}
+		remainder.Offset = int64(len(trip.Transactions))
+		for i, transaction := range trip.Transactions {
+			if transaction.Id == id {
+				remainder.Offset = int64(i)
+			}
+		}
+	}
+
+	remainder.Seed = remainder.Offset
+	if c.IsSet("seed") {
+		remainder.Seed = c.Int64("seed")
+	}
+	return remainder, nil
+}
+
+// reportRemainder tells who carries the minor units left after splitting equally
+func reportRemainder(shares []database.Share, currency string) {
+	var lowest money.Money
+	found := false
+	for _, share := range shares {
+		if share.Auto && (!found || share.Amount < lowest) {
+			lowest, found = share.Amount, true
+		}
+	}
+	for _, share := range shares {
+		if share.Auto && share.Amount != lowest {
+			fmt.Printf("%s  %s carries %s extra to balance the split\n", celebrate(), strings.TrimSpace(share.Member), (share.Amount - lowest).Format(currency))
+		}
+	}
+}
+
+func loadTrip(c *cli.Context) (*database.Trip, error) {
+	name, err := tripName(c)
+	if err != nil {
+		return nil, err
+	}
+	return database.LoadTrip(name)
+}
+
+func parseFilter(c *cli.Context, info *database.TripInfo) (database.Filter, error) {
+	currency := info.Currency
+	filter := database.Filter{
+		Name: c.String("name"),
+	}
+
+	var err error
+	if c.String("member") != "" {
+		names, err := tripMemberNames(c, info.Name)
+		if err != nil {
+			return filter, err
+		}
+		if filter.Member, err = names.resolve(c.String("member")); err != nil {
+			return filter, err
+		}
+	}
+	if c.String("from") != "" {
+		if filter.From, err = parseDate(c.String("from")); err != nil {
+			return filter, fmt.Errorf("Please enter valid from date eg. %s", dateLayout)
+		}
+	}
+	if c.String("to") != "" {
+		if filter.To, err = parseDate(c.String("to")); err != nil {
+			return filter, fmt.Errorf("Please enter valid to date eg. %s", dateLayout)
+		}
+	}
+	if c.String("min") != "" {
+		if filter.MinAmount, err = money.Parse(c.String("min"), currency); err != nil {
+			return filter, fmt.Errorf("Please enter valid min amount")
+		}
+	}
+	if c.String("max") != "" {
+		if filter.MaxAmount, err = money.Parse(c.String("max"), currency); err != nil {
+			return filter, fmt.Errorf("Please enter valid max amount")
+		}
+	}
+	if filter.MinAmount != 0 || filter.MaxAmount != 0 {
+		if filter.Rates, err = database.LoadRates(); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+func printTransactions(transactions []database.Transaction, currency string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDATE\tNAME\tAMOUNT\tPAID BY\tSHARES")
+	for _, transaction := range transactions {
+		amountCurrency := currencyOf(transaction.Currency, currency)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", transaction.Id, transaction.Date.Format(dateLayout), transaction.Name, formatAmount(transaction.Amount, transaction.Currency, currency), formatPayers(transaction, amountCurrency), formatShares(transaction, amountCurrency))
+	}
+	w.Flush()
+}
+
+func printTransaction(transaction *database.Transaction, tripCurrency string) {
+	fmt.Printf("ID:      %d\n", transaction.Id)
+	fmt.Printf("Name:    %s\n", transaction.Name)
+	fmt.Printf("Date:    %s\n", transaction.Date.Format("Jan 2 2006 3:04PM"))
+	fmt.Printf("Amount:  %s\n", formatAmount(transaction.Amount, transaction.Currency, tripCurrency))
+	currency := currencyOf(transaction.Currency, tripCurrency)
+	mode := transactionSplitMode(*transaction)
+	if transaction.Split != "" {
+		fmt.Printf("Split:   %s\n", mode)
+	}
+
+	if len(transaction.Items) > 0 {
+		printItems(transaction, currency)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if isWeighted(mode) {
+		fmt.Fprintln(w, "MEMBER\tSHARE\tPAID\t"+strings.ToUpper(mode.String()))
+	} else {
+		fmt.Fprintln(w, "MEMBER\tSHARE\tPAID")
+	}
+	for _, share := range transaction.Shares {
+		fmt.Fprintf(w, "%s\t%s\t%s", strings.TrimSpace(share.Member), share.Amount.Format(currency), share.Paid.Format(currency))
+		if isWeighted(mode) {
+			fmt.Fprintf(w, "\t%s", formatWeight(share, mode, currency))
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+func formatPayers(transaction database.Transaction, currency string) string {
+	payers := transaction.Payers()
+	if len(payers) == 1 {
+		return strings.TrimSpace(payers[0].Member)
+	}
+	parts := make([]string, len(payers))
+	for i, payer := range payers {
+		parts[i] = fmt.Sprintf("%s %s", strings.TrimSpace(payer.Member), payer.Paid.Format(currency))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatShares(transaction database.Transaction, currency string) string {
+	parts := make([]string, len(transaction.Shares))
+	for i, share := range transaction.Shares {
+		parts[i] = fmt.Sprintf("%s %s", strings.TrimSpace(share.Member), share.Amount.Format(currency))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+	"github.com/urfave/cli"
+)
+
+func tripFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:  "trip, t",
+		Value: "",
+		Usage: "Name or id of the trip. Current trip will be used if not given (Optional)",
+	}
+}
+
+// tripName returns the trip given with --trip or the current trip
+func tripName(c *cli.Context) (string, error) {
+	name := strings.TrimSpace(c.String("trip"))
+	if name != "" {
+		return database.ResolveTrip(name)
+	}
+	return database.CurrentTrip()
+}
+
+// tripInfo returns the metadata of the trip given with --trip or the current trip
+func tripInfo(c *cli.Context) (*database.TripInfo, error) {
+	name, err := tripName(c)
+	if err != nil {
+		return nil, err
+	}
+	return database.GetTrip(name)
+}
+
+//TripCmd used to create/list/use/archive/rename trips
+func TripCmd() cli.Command {
+	return cli.Command{
+		Name:  "trip",
+		Usage: "Manages the trips/groups which keep their transactions separately",
+		Subcommands: []cli.Command{
+			{
+				Name:      "create",
+				Usage:     "Creates new trip",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "members, m",
+						Value: "",
+						Usage: "Comma seperated names eg. gus, walt, jesse etc. (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "currency, c",
+						Value: "",
+						Usage: "Currency code of the trip eg. INR, USD (Optional)",
+					},
+					cli.StringFlag{
+						Name:  "remainder, r",
+						Value: "",
+						Usage: "Who carries the remainder of the equal split: first, payer, round-robin or random (Optional)",
+					},
+					cli.BoolFlag{
+						Name:  "use, u",
+						Usage: "Use the created trip as the current trip",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					name := strings.TrimSpace(c.Args().First())
+					var members []string
+					if c.String("members") != "" {
+						members = strings.Split(c.String("members"), ",")
+					}
+
+					if _, err := splitter.ParseRemainderPolicy(c.String("remainder")); err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+
+					err := database.CreateTrip(name, members, c.String("currency"), c.String("remainder"))
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					if c.Bool("use") {
+						if err := database.UseTrip(name); err != nil {
+							fmt.Printf("%s  %s\n", devil(), err.Error())
+							return nil
+						}
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "Lists the trips",
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "all, a",
+						Usage: "Include archived trips",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					trips, err := database.Trips()
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					current, err := database.CurrentTrip()
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "\tID\tNAME\tCURRENCY\tCREATED\tMEMBERS")
+					for _, trip := range trips {
+						if trip.Archived && !c.Bool("all") {
+							continue
+						}
+						marker := ""
+						if trip.Name == current {
+							marker = "*"
+						} else if trip.Archived {
+							marker = "a"
+						}
+						fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", marker, trip.Id, trip.Name, orDash(trip.Currency), trip.Created.Format("Jan 2 2006"), strings.Join(trip.Members, ", "))
+					}
+					w.Flush()
+					return nil
+				},
+			},
+			{
+				Name:      "use",
+				Usage:     "Uses the trip as the current trip",
+				ArgsUsage: "<name or id>",
+				Action: func(c *cli.Context) error {
+					name, err := database.ResolveTrip(strings.TrimSpace(c.Args().First()))
+					if err == nil {
+						err = database.UseTrip(name)
+					}
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+			{
+				Name:      "archive",
+				Usage:     "Archives the trip. Archived trip can't take new transactions",
+				ArgsUsage: "<name or id>",
+				Action: func(c *cli.Context) error {
+					name, err := database.ResolveTrip(strings.TrimSpace(c.Args().First()))
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					if name == database.DefaultTrip {
+						fmt.Printf("%s  %s\n", devil(), database.ErrArchiveDefaultTrip.Error())
+						return nil
+					}
+					_, yes := waitforinput(fmt.Sprintf("Do you really want to archive %s? (yes/no)", name))
+					if !yes {
+						return nil
+					}
+					err = database.ArchiveTrip(name)
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+			{
+				Name:      "rename",
+				Usage:     "Renames the trip",
+				ArgsUsage: "<name or id> <new name>",
+				Action: func(c *cli.Context) error {
+					name, err := database.ResolveTrip(strings.TrimSpace(c.Args().Get(0)))
+					if err == nil {
+						err = database.RenameTrip(name, strings.TrimSpace(c.Args().Get(1)))
+					}
+					if err != nil {
+						fmt.Printf("%s  %s\n", devil(), err.Error())
+						return nil
+					}
+					fmt.Printf("%s  success\n", celebrate())
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// tripCurrency returns the currency of the trip used for parsing and formatting the amounts
+func tripCurrency(name string) (string, error) {
+	info, err := database.GetTrip(name)
+	if err != nil {
+		return "", err
+	}
+	return info.Currency, nil
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
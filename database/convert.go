@@ -0,0 +1,135 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+)
+
+//Members returns every member involved in the trip as splitter members.
+//The command line only knows member names, so the name is used as the member email too.
+func (trip *Trip) Members() []splitter.Member {
+	members := make([]splitter.Member, 0)
+	seen := make(map[string]bool)
+	for _, transaction := range trip.Transactions {
+		for _, share := range transaction.Shares {
+			if seen[share.Member] {
+				continue
+			}
+			seen[share.Member] = true
+			members = append(members, splitter.Member{
+				Name:  share.Member,
+				Email: share.Member,
+			})
+		}
+	}
+	return members
+}
+
+//Shares converts the stored shares into splitter shares. Each transaction becomes a plan of its own.
+//Settlements are added as transfers without a plan.
+func (trip *Trip) Shares() []splitter.Share {
+	shares := make([]splitter.Share, 0)
+	for _, transaction := range trip.Transactions {
+		for _, share := range transaction.Shares {
+			shares = append(shares, splitter.Share{
+				Id:              share.Id,
+				Tripid:          trip.Id,
+				Planid:          transaction.Id,
+				Memberemail:     share.Member,
+				Membername:      share.Member,
+				Benefactoremail: share.Member,
+				Note:            transaction.Name,
+				Share:           share.Amount,
+				Paid:            share.Paid,
+				Currency:        trip.currencyOf(transaction.Currency),
+				Created:         transaction.Date,
+			})
+		}
+	}
+	// the member who settled paid the other member, which the splitter tallies like a benefactor payment
+	for _, settlement := range trip.Settlements {
+		shares = append(shares, splitter.Share{
+			Id:              settlement.Id,
+			Tripid:          trip.Id,
+			Memberemail:     settlement.From,
+			Membername:      settlement.From,
+			Benefactoremail: settlement.To,
+			Note:            settlement.Note,
+			Paid:            settlement.Amount,
+			Currency:        trip.currencyOf(settlement.Currency),
+			Created:         settlement.Date,
+		})
+	}
+	return shares
+}
+
+//SharesIn converts the shares of the trip to the currency with the stored exchange rates.
+//The currency of the trip is used when the currency is empty.
+func (trip *Trip) SharesIn(currency string) ([]splitter.Share, error) {
+	if currency == "" {
+		currency = trip.Currency
+	}
+	shares := trip.Shares()
+	if !trip.MultiCurrency() && strings.EqualFold(currency, trip.Currency) {
+		return shares, nil
+	}
+	rates, err := LoadRates()
+	if err != nil {
+		return nil, err
+	}
+	return splitter.ConvertShares(shares, currency, rates)
+}
+
+//MultiCurrency tells whether any transaction or settlement of the trip is not in the currency of the trip
+func (trip *Trip) MultiCurrency() bool {
+	for _, transaction := range trip.Transactions {
+		if trip.currencyOf(transaction.Currency) != trip.currencyOf("") {
+			return true
+		}
+	}
+	for _, settlement := range trip.Settlements {
+		if trip.currencyOf(settlement.Currency) != trip.currencyOf("") {
+			return true
+		}
+	}
+	return false
+}
+
+// currencyOf returns the currency of an amount, which is the currency of the trip unless given
+func (trip *Trip) currencyOf(currency string) string {
+	if currency == "" {
+		return strings.ToUpper(trip.Currency)
+	}
+	return strings.ToUpper(currency)
+}
+
+//TotalAmount is the sum of all the transaction amounts in the trip.
+//Amounts in other currencies are added as they are, use SharesIn for converted amounts.
+func (trip *Trip) TotalAmount() money.Money {
+	var total money.Money
+	for _, transaction := range trip.Transactions {
+		total = total + transaction.Amount
+	}
+	return total
+}
+
+//Bill converts the items of the itemised transaction into a splitter bill
+func (transaction *Transaction) Bill() splitter.Bill {
+	bill := splitter.Bill{
+		Items:   make([]splitter.Item, len(transaction.Items)),
+		Tax:     transaction.Tax,
+		Service: transaction.Service,
+		Tip:     transaction.Tip,
+	}
+	for i, item := range transaction.Items {
+		bill.Items[i] = splitter.Item{
+			Name:         item.Name,
+			Price:        item.Price,
+			Quantity:     item.Quantity,
+			Memberemails: item.Members,
+		}
+	}
+	return bill
+}
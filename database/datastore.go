@@ -2,25 +2,34 @@ package database
 
 import (
 	"errors"
-	"time"
+	"sort"
 
 	"github.com/boltdb/bolt"
 )
 
 const (
-	dbName            = "expense.db"
-	defaultBucketName = "default"
+	dbName                = "expense.db"
+	defaultBucketName     = "default"
+	tripsBucketName       = "_trips"
+	settingsBucketName    = "_settings"
+	sharesBucketName      = "_shares" // only used for generating share ids
+	settlementsBucketName = "_settlements"
+	membersBucketName     = "_members"
+	ratesBucketName       = "_rates"
+	remindersBucketName   = "_reminders"   // reminder policy per trip
+	reminderLogBucketName = "_reminderlog" // reminders sent per trip
 )
 
 var (
 	errBucketNotFound = errors.New("Bucket not found")
+	errBucketExists   = errors.New("Bucket already exists")
 )
 
 //StoreData open the DB connection for storing the value
 func storeData(bucketName, key string, value []byte) error {
 	// Open the expense.db data file in your current directory.
 	// It will be created if it doesn't exist.
-	db, err := bolt.Open(dbName, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	db, err := openDB()
 	if err != nil {
 		return err
 	}
@@ -42,12 +51,41 @@ func storeData(bucketName, key string, value []byte) error {
 	return err
 }
 
+//storeAllData open the DB connection for storing all the values in a single transaction
+func storeAllData(bucketName string, values map[string][]byte) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+		if err != nil {
+			return err
+		}
+		// bolt writes faster when the keys are put in order
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := bucket.Put([]byte(key), values[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
+}
+
 //RetriveData open the DB connection for retriving the value
 func retriveData(bucketName, key string) (string, error) {
 	var val string
 	// Open the expense.db data file in your current directory.
 	// It will be created if it doesn't exist.
-	db, err := bolt.Open(dbName, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	db, err := openDB()
 	if err != nil {
 		return string(val), err
 	}
@@ -65,9 +103,160 @@ func retriveData(bucketName, key string) (string, error) {
 	return val, err
 }
 
+//deleteData open the DB connection for deleting the key
+func deleteData(bucketName, key string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketName))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(key))
+	})
+	return err
+}
+
+//forEachData open the DB connection and walks every key/value of the bucket in key order
+func forEachData(bucketName string, fn func(key, value []byte) error) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketName))
+		if bucket == nil {
+			return errBucketNotFound
+		}
+		return bucket.ForEach(fn)
+	})
+	return err
+}
+
+//updateData open the DB connection and runs the update in a single transaction
+func updateData(update func(tx *bolt.Tx) error) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(update)
+}
+
+// renameBucket moves every key of the bucket to the new bucket within the transaction.
+// transform is applied on each value while moving it.
+func renameBucket(tx *bolt.Tx, oldName, newName string, transform func(value []byte) ([]byte, error)) error {
+	oldBucket := tx.Bucket([]byte(oldName))
+	if oldBucket == nil {
+		return nil // nothing stored yet
+	}
+	if tx.Bucket([]byte(newName)) != nil {
+		return errBucketExists
+	}
+	newBucket, err := tx.CreateBucket([]byte(newName))
+	if err != nil {
+		return err
+	}
+	// keep generating ids from where the old bucket stopped
+	if err := newBucket.SetSequence(oldBucket.Sequence()); err != nil {
+		return err
+	}
+	err = oldBucket.ForEach(func(key, value []byte) error {
+		value, err := transform(value)
+		if err != nil {
+			return err
+		}
+		return newBucket.Put(key, value)
+	})
+	if err != nil {
+		return err
+	}
+	return tx.DeleteBucket([]byte(oldName))
+}
+
+//nextSequences open the DB connection and reserves count sequence numbers of the bucket
+func nextSequences(bucketName string, count int) ([]int64, error) {
+	ids := make([]int64, 0, count)
+	db, err := openDB()
+	if err != nil {
+		return ids, err
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+		if err != nil {
+			return err
+		}
+		for i := 0; i < count; i++ {
+			id, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			ids = append(ids, int64(id))
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// rewriteBucket rewrites the values of the bucket within the transaction, a missing bucket is left alone
+func rewriteBucket(tx *bolt.Tx, bucketName string, rewrite func(key, value []byte) ([]byte, error)) error {
+	bucket := tx.Bucket([]byte(bucketName))
+	if bucket == nil {
+		return nil
+	}
+
+	// bolt doesn't allow changing the bucket while iterating it
+	changed := make(map[string][]byte)
+	err := bucket.ForEach(func(key, value []byte) error {
+		newValue, err := rewrite(key, value)
+		if err != nil {
+			return err
+		}
+		if newValue != nil {
+			changed[string(key)] = newValue
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for key, value := range changed {
+		if err := bucket.Put([]byte(key), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteValue rewrites the value of the key within the transaction, a missing value is left alone
+func rewriteValue(tx *bolt.Tx, bucketName, key string, rewrite func(value []byte) ([]byte, error)) error {
+	bucket := tx.Bucket([]byte(bucketName))
+	if bucket == nil {
+		return nil
+	}
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return nil
+	}
+	newValue, err := rewrite(value)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), newValue)
+}
+
 //DeleteBucket deletes the bucket name
 func DeleteBucket(bucketName string) error {
-	db, err := bolt.Open(dbName, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	db, err := openDB()
 	if err != nil {
 		return err
 	}
@@ -78,3 +267,20 @@ func DeleteBucket(bucketName string) error {
 	})
 	return err
 }
+
+// moveData moves the value of the key to the new key within the bucket, within the transaction
+func moveData(tx *bolt.Tx, bucketName, oldKey, newKey string) error {
+	bucket := tx.Bucket([]byte(bucketName))
+	if bucket == nil {
+		return nil
+	}
+	value := bucket.Get([]byte(oldKey))
+	if value == nil {
+		return nil
+	}
+	// the value is only valid until the key is deleted
+	if err := bucket.Put([]byte(newKey), append([]byte{}, value...)); err != nil {
+		return err
+	}
+	return bucket.Delete([]byte(oldKey))
+}
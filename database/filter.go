@@ -0,0 +1,82 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+//Filter narrows down the transactions of a trip. Zero values are ignored.
+type Filter struct {
+	From      time.Time
+	To        time.Time
+	Member    string
+	Name      string
+	MinAmount money.Money // in the currency of the trip
+	MaxAmount money.Money
+	Rates     *money.Rates // converts the amounts in other currencies at the rate of their date to compare them
+}
+
+//Filter returns the transactions matching all the conditions of the filter
+func (trip *Trip) Filter(filter Filter) []Transaction {
+	transactions := make([]Transaction, 0)
+	for _, transaction := range trip.Transactions {
+		if filter.match(trip, transaction) {
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions
+}
+
+func (filter Filter) match(trip *Trip, transaction Transaction) bool {
+	if !filter.From.IsZero() && transaction.Date.Before(filter.From) {
+		return false
+	}
+	// To is inclusive of the whole day
+	if !filter.To.IsZero() && !transaction.Date.Before(filter.To.AddDate(0, 0, 1)) {
+		return false
+	}
+	if filter.Name != "" && !strings.Contains(strings.ToLower(transaction.Name), strings.ToLower(filter.Name)) {
+		return false
+	}
+	if filter.MinAmount != 0 || filter.MaxAmount != 0 {
+		amount, ok := filter.amountIn(trip, transaction)
+		if !ok {
+			return false
+		}
+		if filter.MinAmount != 0 && amount < filter.MinAmount {
+			return false
+		}
+		if filter.MaxAmount != 0 && amount > filter.MaxAmount {
+			return false
+		}
+	}
+	if filter.Member != "" && !hasMember(transaction, filter.Member) {
+		return false
+	}
+	return true
+}
+
+// amountIn returns the amount of the transaction in the currency of the trip.
+// Amounts in other currencies without a rate can't be compared.
+func (filter Filter) amountIn(trip *Trip, transaction Transaction) (money.Money, bool) {
+	from, to := trip.currencyOf(transaction.Currency), trip.currencyOf("")
+	if from == to {
+		return transaction.Amount, true
+	}
+	if filter.Rates == nil {
+		return 0, false
+	}
+	amount, err := filter.Rates.Convert(transaction.Amount, from, to, transaction.Date)
+	return amount, err == nil
+}
+
+func hasMember(transaction Transaction, member string) bool {
+	for _, share := range transaction.Shares {
+		if strings.EqualFold(strings.TrimSpace(share.Member), strings.TrimSpace(member)) {
+			return true
+		}
+	}
+	return false
+}
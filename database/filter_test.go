@@ -0,0 +1,48 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+func TestFilterAmountsInOtherCurrencies(t *testing.T) {
+	date := time.Date(2026, 1, 30, 0, 0, 0, 0, time.Local)
+	trip := &Trip{Currency: "USD", Transactions: []Transaction{
+		{Id: 1, Name: "ramen", Amount: money.FromMinor(500), Currency: "JPY", Date: date},  // 5.00 USD
+		{Id: 2, Name: "sushi", Amount: money.FromMinor(2000), Currency: "JPY", Date: date}, // 20.00 USD
+		{Id: 3, Name: "taxi", Amount: money.FromMinor(1500), Date: date},                   // 15.00 USD
+		{Id: 4, Name: "ferry", Amount: money.FromMinor(3000), Currency: "EUR", Date: date}, // no rate
+	}}
+	rates, err := money.NewRates([]money.Rate{{Base: "USD", Quote: "JPY", Date: date, Value: "100"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		filter Filter
+		want   []int64
+	}{
+		{Filter{MinAmount: money.FromMinor(1000), Rates: rates}, []int64{2, 3}},
+		{Filter{MaxAmount: money.FromMinor(1000), Rates: rates}, []int64{1}},
+		{Filter{MinAmount: money.FromMinor(1000)}, []int64{3}},
+		{Filter{Name: "r"}, []int64{1, 4}},
+	}
+	for _, c := range cases {
+		got := make([]int64, 0)
+		for _, transaction := range trip.Filter(c.filter) {
+			got = append(got, transaction.Id)
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%+v: got %v, want %v", c.filter, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%+v: got %v, want %v", c.filter, got, c.want)
+				break
+			}
+		}
+	}
+}
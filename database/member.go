@@ -0,0 +1,524 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+)
+
+var (
+	//ErrMemberNotFound is returned when no member of the trip has the name or alias
+	ErrMemberNotFound = errors.New("Member not found")
+	//ErrMemberExists is returned when the name or alias is already taken by a member of the trip
+	ErrMemberExists = errors.New("Member already exists")
+	//ErrInvalidMemberName is returned for empty names or names having the separators used in the flags
+	ErrInvalidMemberName = errors.New("Member name should not be empty or contain , or :")
+	//ErrMemberInUse is returned when a member having transactions or settlements is removed
+	ErrMemberInUse = errors.New("Member has transactions or settlements in the trip")
+	//ErrInvalidMemberDates is returned when the member leaves the trip before joining it
+	ErrInvalidMemberDates = errors.New("Member should leave the trip on or after joining it")
+	//ErrMemberOutsideDates is returned when the member has transactions before joining or after leaving the trip
+	ErrMemberOutsideDates = errors.New("Member has transactions outside the dates in the trip")
+)
+
+//UnsettledError is returned when a member who still owes or is owed leaves the trip
+type UnsettledError struct {
+	Member string
+	Net    money.Money // positive when the member is owed, negative when the member owes
+}
+
+func (e *UnsettledError) Error() string {
+	return fmt.Sprintf("%s has to be settled before leaving the trip, the balance is %s", e.Member, e.Net)
+}
+
+//Member is a member of a trip. Transactions refer to the member by its name, aliases are resolved to the name.
+//Members who joined late or left early are part of the equal split only on the days they are in the trip.
+type Member struct {
+	Name     string
+	Email    string   `json:",omitempty"`
+	Avatar   string   `json:",omitempty"`
+	Aliases  []string `json:",omitempty"`
+	Joined   time.Time
+	Left     time.Time // last day in the trip, zero while the member is still in it
+	OptOut   bool      `json:",omitempty"` // member is not reminded by the scheduled reminders
+	Language string    `json:",omitempty"` // language the member reads the briefs in eg. en, es, de or ta
+}
+
+//Active tells whether the member is in the trip on the day of the date
+func (member Member) Active(date time.Time) bool {
+	day := startOfDay(date)
+	if !member.Joined.IsZero() && day.Before(startOfDay(member.Joined)) {
+		return false
+	}
+	return member.Left.IsZero() || !day.After(startOfDay(member.Left))
+}
+
+//NormaliseName trims the name and collapses the spaces within it
+func NormaliseName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+//Members returns the members of the trip. Members who were only named in the transactions are included without email.
+func Members(tripName string) ([]Member, error) {
+	members := make([]Member, 0)
+	result, err := retriveData(membersBucketName, tripName)
+	if err != nil && err != errBucketNotFound {
+		return nil, err
+	}
+	if result != "" {
+		if err := json.Unmarshal([]byte(result), &members); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := GetTrip(tripName)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range info.Members {
+		if _, ok := FindMember(members, name); !ok {
+			members = append(members, Member{Name: NormaliseName(name)})
+		}
+	}
+	return members, nil
+}
+
+//FindMember finds the member by its name, then by its name or aliases ignoring the case
+func FindMember(members []Member, name string) (Member, bool) {
+	name = NormaliseName(name)
+	for _, member := range members {
+		if member.Name == name {
+			return member, true
+		}
+	}
+	for _, member := range members {
+		if member.matches(name) {
+			return member, true
+		}
+	}
+	return Member{}, false
+}
+
+//AddMember adds the member to the trip
+func AddMember(tripName string, member Member) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+	members, err := Members(tripName)
+	if err != nil {
+		return err
+	}
+
+	member, err = normaliseMember(member)
+	if err != nil {
+		return err
+	}
+	for _, name := range append([]string{member.Name}, member.Aliases...) {
+		if taken(members, name, "") {
+			return ErrMemberExists
+		}
+	}
+
+	if err := storeMembers(tripName, append(members, member)); err != nil {
+		return err
+	}
+	return addTripMembers(tripName, []string{member.Name})
+}
+
+//EditMember replaces the member having the name. The transactions and settlements of the member are renamed too.
+func EditMember(tripName, name string, member Member) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+	members, err := Members(tripName)
+	if err != nil {
+		return err
+	}
+	index := indexOfMember(members, name)
+	if index == -1 {
+		return ErrMemberNotFound
+	}
+	old := members[index]
+
+	member, err = normaliseMember(member)
+	if err != nil {
+		return err
+	}
+	for _, name := range append([]string{member.Name}, member.Aliases...) {
+		if taken(members, name, old.Name) {
+			return ErrMemberExists
+		}
+	}
+
+	if !member.Joined.Equal(old.Joined) || !member.Left.Equal(old.Left) {
+		trip, err := LoadTrip(tripName)
+		if err != nil {
+			return err
+		}
+		// the transactions still carry the old name
+		dates := member
+		dates.Name = old.Name
+		if !trip.within(dates) {
+			return ErrMemberOutsideDates
+		}
+	}
+
+	members[index] = member
+	if member.Name == old.Name {
+		return storeMembers(tripName, members)
+	}
+	return renameMember(tripName, members, old.Name, member.Name)
+}
+
+//RemoveMember removes the member from the trip. Members having transactions or settlements can't be removed.
+func RemoveMember(tripName, name string) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+	members, err := Members(tripName)
+	if err != nil {
+		return err
+	}
+	index := indexOfMember(members, name)
+	if index == -1 {
+		return ErrMemberNotFound
+	}
+	member := members[index]
+
+	trip, err := LoadTrip(tripName)
+	if err != nil {
+		return err
+	}
+	if trip.involves(member.Name) {
+		return ErrMemberInUse
+	}
+
+	if err := storeMembers(tripName, append(members[:index], members[index+1:]...)); err != nil {
+		return err
+	}
+	info, err := GetTrip(tripName)
+	if err != nil {
+		return err
+	}
+	info.Members = removeName(info.Members, member.Name)
+	return storeTripInfo(info)
+}
+
+//JoinMember sets the day the member joined the trip. A member who left rejoins the trip.
+//The day the member joined is kept when the date is zero.
+func JoinMember(tripName, name string, date time.Time) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+	members, err := Members(tripName)
+	if err != nil {
+		return err
+	}
+	index := indexOfMember(members, name)
+	if index == -1 {
+		return ErrMemberNotFound
+	}
+	member := &members[index]
+	if !date.IsZero() {
+		member.Joined = startOfDay(date)
+	}
+	member.Left = time.Time{}
+
+	trip, err := LoadTrip(tripName)
+	if err != nil {
+		return err
+	}
+	if !trip.within(*member) {
+		return ErrMemberOutsideDates
+	}
+	return storeMembers(tripName, members)
+}
+
+//LeaveMember sets the last day of the member in the trip. The member has to be settled before leaving.
+func LeaveMember(tripName, name string, date time.Time) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+	members, err := Members(tripName)
+	if err != nil {
+		return err
+	}
+	index := indexOfMember(members, name)
+	if index == -1 {
+		return ErrMemberNotFound
+	}
+	member := &members[index]
+	if !member.Joined.IsZero() && startOfDay(date).Before(member.Joined) {
+		return ErrInvalidMemberDates
+	}
+
+	trip, err := LoadTrip(tripName)
+	if err != nil {
+		return err
+	}
+	shares, err := trip.SharesIn("")
+	if err != nil {
+		return err
+	}
+	for _, balance := range splitter.CreateBalances(trip.Id, trip.Members(), shares) {
+		if balance.Memberemail == member.Name && balance.Net != 0 {
+			return &UnsettledError{Member: member.Name, Net: balance.Net}
+		}
+	}
+
+	member.Left = startOfDay(date)
+	if !trip.within(*member) {
+		return ErrMemberOutsideDates
+	}
+	return storeMembers(tripName, members)
+}
+
+//AddAlias lets the member be called by the alias too
+func AddAlias(tripName, name, alias string) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+	members, err := Members(tripName)
+	if err != nil {
+		return err
+	}
+	index := indexOfMember(members, name)
+	if index == -1 {
+		return ErrMemberNotFound
+	}
+
+	alias = NormaliseName(alias)
+	if !validMemberName(alias) {
+		return ErrInvalidMemberName
+	}
+	if taken(members, alias, "") {
+		return ErrMemberExists
+	}
+	members[index].Aliases = append(members[index].Aliases, alias)
+	return storeMembers(tripName, members)
+}
+
+func (member Member) matches(name string) bool {
+	if strings.EqualFold(member.Name, name) {
+		return true
+	}
+	for _, alias := range member.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// involves tells whether the member has a share, an item or a settlement in the trip
+func (trip *Trip) involves(name string) bool {
+	for _, transaction := range trip.Transactions {
+		for _, share := range transaction.Shares {
+			if share.Member == name {
+				return true
+			}
+		}
+		for _, item := range transaction.Items {
+			for _, member := range item.Members {
+				if member == name {
+					return true
+				}
+			}
+		}
+	}
+	for _, settlement := range trip.Settlements {
+		if settlement.From == name || settlement.To == name {
+			return true
+		}
+	}
+	return false
+}
+
+// within tells whether every transaction shared by the member falls in the days the member is in the trip
+func (trip *Trip) within(member Member) bool {
+	for _, transaction := range trip.Transactions {
+		if member.Active(transaction.Date) {
+			continue
+		}
+		for _, share := range transaction.Shares {
+			if share.Member == member.Name && (share.Auto || share.Amount != 0 || share.Weight != 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// renameMember stores the members and renames the member in the transactions, the settlements, the reminder log
+// and the metadata of the trip, all in a single transaction so that no share is left with a name the trip doesn't know
+func renameMember(tripName string, members []Member, oldName, newName string) error {
+	rename := func(name string) string {
+		if name == oldName {
+			return newName
+		}
+		return name
+	}
+	membersJSON, err := json.Marshal(members)
+	if err != nil {
+		return err
+	}
+
+	return updateData(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(membersBucketName))
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte(tripName), membersJSON); err != nil {
+			return err
+		}
+
+		err = rewriteBucket(tx, tripName, func(key, value []byte) ([]byte, error) {
+			day, err := readDay(key, value)
+			if err != nil {
+				return nil, err
+			}
+			for i := range day.Transactions {
+				transaction := &day.Transactions[i]
+				for j := range transaction.Shares {
+					transaction.Shares[j].Member = rename(transaction.Shares[j].Member)
+				}
+				for j := range transaction.Items {
+					for k := range transaction.Items[j].Members {
+						transaction.Items[j].Members[k] = rename(transaction.Items[j].Members[k])
+					}
+				}
+			}
+			return json.Marshal(day)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = rewriteValue(tx, settlementsBucketName, tripName, func(value []byte) ([]byte, error) {
+			settlements := make([]Settlement, 0)
+			if err := json.Unmarshal(value, &settlements); err != nil {
+				return nil, err
+			}
+			for i := range settlements {
+				settlements[i].From = rename(settlements[i].From)
+				settlements[i].To = rename(settlements[i].To)
+			}
+			return json.Marshal(settlements)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = rewriteValue(tx, reminderLogBucketName, tripName, func(value []byte) ([]byte, error) {
+			reminders := make([]Reminder, 0)
+			if err := json.Unmarshal(value, &reminders); err != nil {
+				return nil, err
+			}
+			for i := range reminders {
+				reminders[i].Member = rename(reminders[i].Member)
+			}
+			return json.Marshal(reminders)
+		})
+		if err != nil {
+			return err
+		}
+
+		return rewriteValue(tx, tripsBucketName, tripName, func(value []byte) ([]byte, error) {
+			info := &TripInfo{}
+			if err := json.Unmarshal(value, info); err != nil {
+				return nil, err
+			}
+			for i := range info.Members {
+				info.Members[i] = rename(info.Members[i])
+			}
+			return json.Marshal(info)
+		})
+	})
+}
+
+// startOfDay is the midnight of the date in the local time
+func startOfDay(date time.Time) time.Time {
+	date = date.In(time.Local)
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func normaliseMember(member Member) (Member, error) {
+	member.Name = NormaliseName(member.Name)
+	member.Email = strings.TrimSpace(member.Email)
+	member.Avatar = strings.TrimSpace(member.Avatar)
+	aliases := make([]string, 0, len(member.Aliases))
+	for _, alias := range member.Aliases {
+		alias = NormaliseName(alias)
+		if !validMemberName(alias) {
+			return member, ErrInvalidMemberName
+		}
+		if !strings.EqualFold(alias, member.Name) {
+			aliases = append(aliases, alias)
+		}
+	}
+	member.Aliases = aliases
+	if !validMemberName(member.Name) {
+		return member, ErrInvalidMemberName
+	}
+	if !member.Joined.IsZero() {
+		member.Joined = startOfDay(member.Joined)
+	}
+	if !member.Left.IsZero() {
+		member.Left = startOfDay(member.Left)
+		if member.Left.Before(member.Joined) {
+			return member, ErrInvalidMemberDates
+		}
+	}
+	return member, nil
+}
+
+func validMemberName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, ",:")
+}
+
+// taken tells whether a member other than except is called by the name
+func taken(members []Member, name string, except string) bool {
+	for _, member := range members {
+		if member.Name != except && member.matches(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOfMember(members []Member, name string) int {
+	member, ok := FindMember(members, name)
+	if !ok {
+		return -1
+	}
+	for i := range members {
+		if members[i].Name == member.Name {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeName(names []string, name string) []string {
+	result := make([]string, 0, len(names))
+	for _, existing := range names {
+		if NormaliseName(existing) != name {
+			result = append(result, existing)
+		}
+	}
+	return result
+}
+
+func storeMembers(tripName string, members []Member) error {
+	json, err := json.Marshal(members)
+	if err != nil {
+		return err
+	}
+	return storeData(membersBucketName, tripName, json)
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+func TestEditMemberRenames(t *testing.T) {
+	defer inTempDir(t)()
+	if err := CreateTrip("goa", []string{"walt", "jesse"}, "EUR", ""); err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 1, 30, 20, 0, 0, 0, time.Local)
+	if _, err := NewTrip("goa", testTransaction("taxi", date)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddSettlement("goa", Settlement{From: "jesse", To: "walt", Amount: money.FromMinor(1500), Date: date}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LogReminder("goa", Reminder{Member: "jesse", Amount: money.FromMinor(1500), Sent: date}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := EditMember("goa", "jesse", Member{Name: "pinkman", Aliases: []string{"jesse"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	trip, err := LoadTrip("goa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shares := trip.Transactions[0].Shares; shares[0].Member != "walt" || shares[1].Member != "pinkman" {
+		t.Errorf("got shares %+v", shares)
+	}
+	if settlement := trip.Settlements[0]; settlement.From != "pinkman" || settlement.To != "walt" {
+		t.Errorf("got settlement %+v", settlement)
+	}
+	reminders, err := Reminders("goa")
+	if err != nil || len(reminders) != 1 || reminders[0].Member != "pinkman" {
+		t.Errorf("got reminders %+v, %v", reminders, err)
+	}
+	info, err := GetTrip("goa")
+	if err != nil || len(info.Members) != 2 || info.Members[1] != "pinkman" {
+		t.Errorf("got trip %+v, %v", info, err)
+	}
+	members, err := Members("goa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member, ok := FindMember(members, "jesse"); !ok || member.Name != "pinkman" {
+		t.Errorf("got members %+v", members)
+	}
+
+	if err := EditMember("goa", "pinkman", Member{Name: "walt"}); err != ErrMemberExists {
+		t.Errorf("expected ErrMemberExists, got %v", err)
+	}
+}
+
+func TestJoinAndLeaveMember(t *testing.T) {
+	defer inTempDir(t)()
+	if err := CreateTrip("goa", []string{"walt", "jesse", "gus"}, "EUR", ""); err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 1, 30, 20, 0, 0, 0, time.Local)
+	next := date.AddDate(0, 0, 1)
+	if _, err := NewTrip("goa", testTransaction("taxi", date)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := JoinMember("goa", "gus", next); err != nil {
+		t.Fatal(err)
+	}
+	if err := JoinMember("goa", "jesse", next); err != ErrMemberOutsideDates {
+		t.Errorf("expected ErrMemberOutsideDates for the share before joining, got %v", err)
+	}
+	if err := LeaveMember("goa", "gus", date); err != ErrInvalidMemberDates {
+		t.Errorf("expected ErrInvalidMemberDates, got %v", err)
+	}
+
+	var unsettled *UnsettledError
+	if err := LeaveMember("goa", "jesse", next); !errors.As(err, &unsettled) || unsettled.Net != money.FromMinor(-1500) {
+		t.Errorf("expected jesse to be unsettled, got %v", err)
+	}
+	if _, err := AddSettlement("goa", Settlement{From: "jesse", To: "walt", Amount: money.FromMinor(1500), Date: next}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LeaveMember("goa", "jesse", date.AddDate(0, 0, -1)); err != ErrMemberOutsideDates {
+		t.Errorf("expected ErrMemberOutsideDates for the share after leaving, got %v", err)
+	}
+	if err := LeaveMember("goa", "jesse", next); err != nil {
+		t.Fatal(err)
+	}
+
+	members, err := Members("goa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gus, _ := FindMember(members, "gus")
+	jesse, _ := FindMember(members, "jesse")
+	if !gus.Joined.Equal(startOfDay(next)) || gus.Active(date) || !gus.Active(next) {
+		t.Errorf("got gus %+v", gus)
+	}
+	if !jesse.Left.Equal(startOfDay(next)) || !jesse.Active(next) || jesse.Active(next.AddDate(0, 0, 1)) {
+		t.Errorf("got jesse %+v", jesse)
+	}
+
+	// joining again without a date rejoins the trip
+	if err := JoinMember("goa", "jesse", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	members, err = Members("goa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jesse, _ := FindMember(members, "jesse"); !jesse.Left.IsZero() || !jesse.Joined.IsZero() {
+		t.Errorf("got jesse %+v", jesse)
+	}
+}
@@ -0,0 +1,256 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// schemaVersionKey keeps the version of the layout of the stored data in the settings
+const schemaVersionKey = "schemaversion"
+
+// migrations bring the data stored by older versions up to date, schemaVersion is the number of migrations.
+// Each runs in the same transaction as the update of the version. Never reorder them, only append.
+var migrations = []func(tx *bolt.Tx) error{
+	migrateIDs,
+	migrateDayKeys,
+}
+
+var schemaVersion = len(migrations)
+
+//ErrNewerSchema is returned when the store was written by a newer version, which an older version can't read safely
+var ErrNewerSchema = errors.New("Store was written by a newer version of the tool, please upgrade")
+
+var (
+	migrateMu sync.Mutex
+	migrated  bool
+)
+
+// openDB opens the store. The first open of the process migrates the data stored by older versions,
+// so the reads never have to write.
+func openDB() (*bolt.DB, error) {
+	db, err := bolt.Open(dbName, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrate runs the migrations newer than the version stored, once per process
+func migrate(db *bolt.DB) error {
+	migrateMu.Lock()
+	defer migrateMu.Unlock()
+	if migrated {
+		return nil
+	}
+
+	version := 0
+	err := db.View(func(tx *bolt.Tx) error {
+		var err error
+		version, err = storedSchemaVersion(tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if version < schemaVersion {
+		err = db.Update(func(tx *bolt.Tx) error {
+			// another process may have migrated meanwhile
+			version, err := storedSchemaVersion(tx)
+			if err != nil || version == schemaVersion {
+				return err
+			}
+			for _, migration := range migrations[version:] {
+				if err := migration(tx); err != nil {
+					return err
+				}
+			}
+			settings, err := tx.CreateBucketIfNotExists([]byte(settingsBucketName))
+			if err != nil {
+				return err
+			}
+			return settings.Put([]byte(schemaVersionKey), []byte(strconv.Itoa(schemaVersion)))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	migrated = true
+	return nil
+}
+
+// storedSchemaVersion is the version the store was last migrated to, 0 for the stores older than the version.
+// Stores of a newer version are refused instead of being migrated again.
+func storedSchemaVersion(tx *bolt.Tx) (int, error) {
+	settings := tx.Bucket([]byte(settingsBucketName))
+	if settings == nil {
+		return 0, nil
+	}
+	value := settings.Get([]byte(schemaVersionKey))
+	if value == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0, err
+	}
+	if version > schemaVersion {
+		return version, ErrNewerSchema
+	}
+	return version, nil
+}
+
+// migrateIDs generates ids for the trips, transactions and shares stored before ids were introduced
+func migrateIDs(tx *bolt.Tx) error {
+	nextID := func(bucketName string) (int64, error) {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+		if err != nil {
+			return 0, err
+		}
+		id, err := bucket.NextSequence()
+		return int64(id), err
+	}
+
+	tripNames, err := tripBuckets(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := rewriteBucket(tx, tripsBucketName, func(key, value []byte) ([]byte, error) {
+		info := &TripInfo{}
+		if err := json.Unmarshal(value, info); err != nil {
+			return nil, err
+		}
+		if info.Id != 0 {
+			return nil, nil
+		}
+		var err error
+		if info.Id, err = nextID(tripsBucketName); err != nil {
+			return nil, err
+		}
+		return json.Marshal(info)
+	}); err != nil {
+		return err
+	}
+
+	for _, tripName := range tripNames {
+		err := rewriteBucket(tx, tripName, func(key, value []byte) ([]byte, error) {
+			day := &Trip{}
+			if err := json.Unmarshal(value, day); err != nil {
+				return nil, err
+			}
+
+			changed := false
+			for i := range day.Transactions {
+				transaction := &day.Transactions[i]
+				if transaction.Id == 0 {
+					id, err := nextID(tripName)
+					if err != nil {
+						return nil, err
+					}
+					transaction.Id, changed = id, true
+				}
+				for j := range transaction.Shares {
+					if transaction.Shares[j].Id == 0 {
+						id, err := nextID(sharesBucketName)
+						if err != nil {
+							return nil, err
+						}
+						transaction.Shares[j].Id, changed = id, true
+					}
+				}
+			}
+			if !changed {
+				return nil, nil
+			}
+			return json.Marshal(day)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateDayKeys moves the days keyed by their UTC date to the local date of their transactions
+func migrateDayKeys(tx *bolt.Tx) error {
+	tripNames, err := tripBuckets(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, tripName := range tripNames {
+		bucket := tx.Bucket([]byte(tripName))
+		days := make(map[string]*Trip)
+		var keys []string
+		changed := false
+		err := bucket.ForEach(func(key, value []byte) error {
+			keys = append(keys, string(key))
+			day := &Trip{}
+			if err := json.Unmarshal(value, day); err != nil {
+				return err
+			}
+			for _, transaction := range day.Transactions {
+				newKey := string(key)
+				if !transaction.Date.IsZero() {
+					newKey = dayKey(transaction.Date)
+				} else if keyDate, err := time.Parse(time.RFC3339, string(key)); err == nil {
+					// transactions stored before the date was recorded keep the date of their key
+					newKey = dayKey(time.Date(keyDate.Year(), keyDate.Month(), keyDate.Day(), 0, 0, 0, 0, time.Local))
+				}
+				if newKey != string(key) {
+					changed = true
+				}
+				if days[newKey] == nil {
+					days[newKey] = &Trip{Name: tripName}
+				}
+				days[newKey].Transactions = append(days[newKey].Transactions, transaction)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+
+		for _, key := range keys {
+			if err := bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		for key, day := range days {
+			value, err := json.Marshal(day)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(key), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tripBuckets lists the buckets holding the transactions of the trips. Trip names never start with _,
+// unlike the buckets kept for the store.
+func tripBuckets(tx *bolt.Tx) ([]string, error) {
+	var tripNames []string
+	err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		if !strings.HasPrefix(string(name), "_") {
+			tripNames = append(tripNames, string(name))
+		}
+		return nil
+	})
+	return tripNames, err
+}
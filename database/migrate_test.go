@@ -0,0 +1,96 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// storeLegacy writes the data the way the versions before the migrations did, without ids and keyed by the UTC date
+func storeLegacy(t *testing.T, version string) {
+	t.Helper()
+	db, err := bolt.Open(dbName, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.Update(func(tx *bolt.Tx) error {
+		values := map[string]map[string]string{
+			tripsBucketName: {"goa": `{"Name":"goa","Currency":"EUR"}`},
+			"goa": {"2026-01-30T00:00:00Z": `{"Name":"goa","Transactions":[` +
+				`{"Name":"taxi","Date":"2026-01-31T01:00:00+05:30","Amount":3000,"Shares":[{"Member":"walt","Amount":1500,"Paid":3000},{"Member":"jesse","Amount":1500}]},` +
+				`{"Name":"tea","Amount":400,"Shares":[{"Member":"walt","Amount":200},{"Member":"jesse","Amount":200,"Paid":400}]}]}`},
+		}
+		if version != "" {
+			values[settingsBucketName] = map[string]string{schemaVersionKey: version}
+		}
+		for bucketName, keys := range values {
+			bucket, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+			if err != nil {
+				return err
+			}
+			for key, value := range keys {
+				if err := bucket.Put([]byte(key), []byte(value)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	defer inTempDir(t)()
+	local := time.Local
+	defer func() { time.Local = local }()
+	time.Local = time.FixedZone("IST", 5*60*60+30*60)
+	storeLegacy(t, "")
+
+	for i := 0; i < 2; i++ { // the second migration should find nothing to do
+		migrated = false
+		trip, err := LoadTrip("goa")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trip.Id != 1 || len(trip.Transactions) != 2 {
+			t.Fatalf("got %+v", trip)
+		}
+		// the taxi was on the 31st in India, the tea keeps the date of its key
+		tea, taxi := trip.Transactions[0], trip.Transactions[1]
+		if tea.Name != "tea" || tea.Id != 2 || tea.Shares[0].Id != 3 || tea.Shares[1].Id != 4 {
+			t.Errorf("got %+v", tea)
+		}
+		if taxi.Name != "taxi" || taxi.Id != 1 || taxi.Shares[0].Id != 1 || taxi.Shares[1].Id != 2 {
+			t.Errorf("got %+v", taxi)
+		}
+		if keys := dayKeys(t, "goa"); len(keys) != 2 || keys[0] != "2026-01-30T00:00:00+05:30" || keys[1] != "2026-01-31T00:00:00+05:30" {
+			t.Errorf("got days %v", keys)
+		}
+		if version, err := retriveData(settingsBucketName, schemaVersionKey); err != nil || version != "2" {
+			t.Errorf("got version %q, %v", version, err)
+		}
+	}
+
+	// the ids go on from the migrated ones
+	id, err := NewTrip("goa", testTransaction("dinner", time.Date(2026, 1, 31, 20, 0, 0, 0, time.Local)))
+	if err != nil || id != 3 {
+		t.Errorf("got id %d, %v", id, err)
+	}
+}
+
+func TestMigrateNewerSchema(t *testing.T) {
+	defer inTempDir(t)()
+	storeLegacy(t, "99")
+
+	if _, err := LoadTrip("goa"); err != ErrNewerSchema {
+		t.Fatalf("expected ErrNewerSchema, got %v", err)
+	}
+	migrated = true // read the store as it is
+	if keys := dayKeys(t, "goa"); len(keys) != 1 || keys[0] != "2026-01-30T00:00:00Z" {
+		t.Errorf("the store was changed, got days %v", keys)
+	}
+}
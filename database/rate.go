@@ -0,0 +1,127 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+//ErrRateNotFound is returned when no rate is stored for the currencies on the date
+var ErrRateNotFound = errors.New("Rate not found")
+
+//DefaultRatePivot is the currency the missing rates are derived through unless another one is set.
+//It is the base of the ECB reference rates.
+const DefaultRatePivot = money.ECBBase
+
+const ratePivotKey = "ratepivot"
+
+//SetRate stores the exchange rate effective from its date. Rates are shared by all the trips.
+func SetRate(rate money.Rate) error {
+	rate = normaliseRate(rate)
+	if _, err := money.NewRates([]money.Rate{rate}); err != nil {
+		return err
+	}
+
+	json, err := json.Marshal(rate)
+	if err != nil {
+		return err
+	}
+	return storeData(ratesBucketName, rateKey(rate.Base, rate.Quote, rate.Date), json)
+}
+
+//ImportRates stores all the rates at once. Rates already stored for the same currencies and date are replaced.
+func ImportRates(rates []money.Rate) error {
+	values := make(map[string][]byte, len(rates))
+	for _, rate := range rates {
+		rate = normaliseRate(rate)
+		if _, err := money.NewRates([]money.Rate{rate}); err != nil {
+			return err
+		}
+		json, err := json.Marshal(rate)
+		if err != nil {
+			return err
+		}
+		values[rateKey(rate.Base, rate.Quote, rate.Date)] = json
+	}
+	return storeAllData(ratesBucketName, values)
+}
+
+//RatePivot returns the currency the missing rates are derived through
+func RatePivot() (string, error) {
+	result, err := retriveData(settingsBucketName, ratePivotKey)
+	if err != nil && err != errBucketNotFound {
+		return "", err
+	}
+	if result == "" {
+		return DefaultRatePivot, nil
+	}
+	return result, nil
+}
+
+//SetRatePivot persists the currency the missing rates are derived through
+func SetRatePivot(currency string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return money.ErrInvalidRate
+	}
+	return storeData(settingsBucketName, ratePivotKey, []byte(currency))
+}
+
+//DeleteRate removes the rate between the currencies set on the date
+func DeleteRate(base, quote string, date time.Time) error {
+	key := rateKey(strings.ToUpper(strings.TrimSpace(base)), strings.ToUpper(strings.TrimSpace(quote)), startOfDay(date))
+	result, err := retriveData(ratesBucketName, key)
+	if err != nil && err != errBucketNotFound {
+		return err
+	}
+	if result == "" {
+		return ErrRateNotFound
+	}
+	return deleteData(ratesBucketName, key)
+}
+
+//ListRates returns the stored rates ordered by the currencies and the date
+func ListRates() ([]money.Rate, error) {
+	rates := make([]money.Rate, 0)
+	err := forEachData(ratesBucketName, func(key, value []byte) error {
+		rate := money.Rate{}
+		if err := json.Unmarshal(value, &rate); err != nil {
+			return err
+		}
+		rates = append(rates, rate)
+		return nil
+	})
+	if err != nil && err != errBucketNotFound {
+		return nil, err
+	}
+	return rates, nil
+}
+
+//LoadRates returns the table of the stored rates used for converting the amounts
+func LoadRates() (*money.Rates, error) {
+	rates, err := ListRates()
+	if err != nil {
+		return nil, err
+	}
+	pivot, err := RatePivot()
+	if err != nil {
+		return nil, err
+	}
+	return money.NewRates(rates, pivot, DefaultRatePivot)
+}
+
+func normaliseRate(rate money.Rate) money.Rate {
+	rate.Base = strings.ToUpper(strings.TrimSpace(rate.Base))
+	rate.Quote = strings.ToUpper(strings.TrimSpace(rate.Quote))
+	rate.Value = strings.TrimSpace(rate.Value)
+	rate.Date = startOfDay(rate.Date)
+	return rate
+}
+
+// rateKey sorts the rates by the currencies and then by the date
+func rateKey(base, quote string, date time.Time) string {
+	return base + "/" + quote + "/" + date.Format("2006-01-02")
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/reminder"
+)
+
+//Reminder is an entry of the log of the scheduled reminders kept per trip.
+//An entry marked settled closes the reminders sent to the member before it.
+type Reminder struct {
+	Member    string
+	Email     string      `json:",omitempty"`
+	Amount    money.Money // amount the member owed when reminded
+	Currency  string      `json:",omitempty"`
+	Sent      time.Time
+	Escalated bool `json:",omitempty"` // creditors were copied in
+	Settled   bool `json:",omitempty"`
+}
+
+//ReminderPolicy returns the reminder policy of the trip, the default policy if none is set
+func ReminderPolicy(tripName string) (reminder.Policy, error) {
+	policy := reminder.DefaultPolicy
+	result, err := retriveData(remindersBucketName, tripName)
+	if err != nil && err != errBucketNotFound {
+		return policy, err
+	}
+	if result == "" {
+		return policy, nil
+	}
+	err = json.Unmarshal([]byte(result), &policy)
+	return policy, err
+}
+
+//SetReminderPolicy stores the reminder policy of the trip
+func SetReminderPolicy(tripName string, policy reminder.Policy) error {
+	if _, err := GetTrip(tripName); err != nil {
+		return err
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	return storeData(remindersBucketName, tripName, data)
+}
+
+//Reminders returns the log of the reminders of the trip, oldest first
+func Reminders(tripName string) ([]Reminder, error) {
+	reminders := make([]Reminder, 0)
+	result, err := retriveData(reminderLogBucketName, tripName)
+	if err != nil && err != errBucketNotFound {
+		return nil, err
+	}
+	if result == "" {
+		return reminders, nil
+	}
+	err = json.Unmarshal([]byte(result), &reminders)
+	return reminders, err
+}
+
+//LogReminder appends the reminder to the log of the trip
+func LogReminder(tripName string, entry Reminder) error {
+	reminders, err := Reminders(tripName)
+	if err != nil {
+		return err
+	}
+	return storeReminders(tripName, append(reminders, entry))
+}
+
+//OptOutMember stops or resumes the scheduled reminders of the member
+func OptOutMember(tripName, name string, optOut bool) error {
+	members, err := Members(tripName)
+	if err != nil {
+		return err
+	}
+	index := indexOfMember(members, name)
+	if index == -1 {
+		return ErrMemberNotFound
+	}
+	members[index].OptOut = optOut
+	return storeMembers(tripName, members)
+}
+
+//RemindersSince returns the reminders sent to the member after the member was last settled
+func RemindersSince(reminders []Reminder, member string) []Reminder {
+	sent := make([]Reminder, 0)
+	for _, entry := range reminders {
+		if entry.Member != member {
+			continue
+		}
+		if entry.Settled {
+			sent = sent[:0]
+			continue
+		}
+		sent = append(sent, entry)
+	}
+	return sent
+}
+
+func storeReminders(tripName string, reminders []Reminder) error {
+	data, err := json.Marshal(reminders)
+	if err != nil {
+		return err
+	}
+	return storeData(reminderLogBucketName, tripName, data)
+}
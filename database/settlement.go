@@ -0,0 +1,130 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+var (
+	//ErrSettlementNotFound is returned when there is no settlement with the id in the trip
+	ErrSettlementNotFound = errors.New("Settlement not found")
+	//ErrInvalidSettlement is returned when the settlement is not between two members or has no amount
+	ErrInvalidSettlement = errors.New("Settlement should be a positive amount paid by a member to another member")
+)
+
+//Settlement is a repayment made by one member to another after the suggestions.
+//It is kept per trip and fed to the splitter as a transfer between the two members.
+type Settlement struct {
+	Id        int64
+	From      string // member who paid
+	To        string // member who got paid
+	Amount    money.Money
+	Currency  string `json:",omitempty"` // currency of the amount when it is not the currency of the trip
+	Date      time.Time
+	Note      string
+	Reference string // reference of the payment eg. a bank transfer id (Optional)
+}
+
+//AddSettlement records the settlement in the trip and returns the id generated for it
+func AddSettlement(tripName string, settlement Settlement) (int64, error) {
+	if err := checkWritable(tripName); err != nil {
+		return 0, err
+	}
+	settlement.From = NormaliseName(settlement.From)
+	settlement.To = NormaliseName(settlement.To)
+	if settlement.From == "" || settlement.To == "" || settlement.From == settlement.To || settlement.Amount <= 0 {
+		return 0, ErrInvalidSettlement
+	}
+	if settlement.Date.IsZero() {
+		settlement.Date = time.Now()
+	}
+
+	settlements, err := Settlements(tripName)
+	if err != nil {
+		return 0, err
+	}
+	ids, err := nextSequences(settlementsBucketName, 1)
+	if err != nil {
+		return 0, err
+	}
+	settlement.Id = ids[0]
+	settlements = append(settlements, settlement)
+	return settlement.Id, storeSettlements(tripName, settlements)
+}
+
+//Settlements returns the settlements of the trip, oldest first
+func Settlements(tripName string) ([]Settlement, error) {
+	settlements := make([]Settlement, 0)
+	result, err := retriveData(settlementsBucketName, tripName)
+	if err != nil && err != errBucketNotFound {
+		return nil, err
+	}
+	if result == "" {
+		return settlements, nil
+	}
+	if err := json.Unmarshal([]byte(result), &settlements); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(settlements, func(i, j int) bool { return settlements[i].Date.Before(settlements[j].Date) })
+	return settlements, nil
+}
+
+//UpdateSettlement replaces the settlement having the id
+func UpdateSettlement(tripName string, id int64, settlement Settlement) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+	settlement.From = NormaliseName(settlement.From)
+	settlement.To = NormaliseName(settlement.To)
+	if settlement.From == "" || settlement.To == "" || settlement.From == settlement.To || settlement.Amount <= 0 {
+		return ErrInvalidSettlement
+	}
+
+	settlements, err := Settlements(tripName)
+	if err != nil {
+		return err
+	}
+	for i := range settlements {
+		if settlements[i].Id == id {
+			settlement.Id = id
+			if settlement.Date.IsZero() {
+				settlement.Date = settlements[i].Date
+			}
+			settlements[i] = settlement
+			return storeSettlements(tripName, settlements)
+		}
+	}
+	return ErrSettlementNotFound
+}
+
+//DeleteSettlement removes the settlement having the id from the trip
+func DeleteSettlement(tripName string, id int64) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+	settlements, err := Settlements(tripName)
+	if err != nil {
+		return err
+	}
+	for i, settlement := range settlements {
+		if settlement.Id == id {
+			return storeSettlements(tripName, append(settlements[:i], settlements[i+1:]...))
+		}
+	}
+	return ErrSettlementNotFound
+}
+
+func storeSettlements(tripName string, settlements []Settlement) error {
+	if len(settlements) == 0 {
+		return deleteData(settlementsBucketName, tripName)
+	}
+	json, err := json.Marshal(settlements)
+	if err != nil {
+		return err
+	}
+	return storeData(settlementsBucketName, tripName, json)
+}
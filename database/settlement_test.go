@@ -0,0 +1,70 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+func TestSettlements(t *testing.T) {
+	defer inTempDir(t)()
+	if err := CreateTrip("goa", []string{"walt", "jesse"}, "EUR", ""); err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 1, 30, 20, 0, 0, 0, time.Local)
+	later, err := AddSettlement("goa", Settlement{From: " jesse ", To: "walt", Amount: money.FromMinor(1000), Date: date.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	earlier, err := AddSettlement("goa", Settlement{From: "jesse", To: "walt", Amount: money.FromMinor(500), Date: date})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, invalid := range []Settlement{
+		{From: "jesse", To: "jesse", Amount: money.FromMinor(500)},
+		{From: "jesse", To: "walt"},
+		{From: "", To: "walt", Amount: money.FromMinor(500)},
+	} {
+		if _, err := AddSettlement("goa", invalid); err != ErrInvalidSettlement {
+			t.Errorf("expected ErrInvalidSettlement for %+v, got %v", invalid, err)
+		}
+	}
+
+	settlements, err := Settlements("goa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// oldest first
+	if len(settlements) != 2 || settlements[0].Id != earlier || settlements[1].Id != later || settlements[1].From != "jesse" {
+		t.Errorf("got %+v", settlements)
+	}
+
+	if err := UpdateSettlement("goa", later, Settlement{From: "walt", To: "jesse", Amount: money.FromMinor(200), Note: "change"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateSettlement("goa", 99, Settlement{From: "walt", To: "jesse", Amount: money.FromMinor(200)}); err != ErrSettlementNotFound {
+		t.Errorf("expected ErrSettlementNotFound, got %v", err)
+	}
+	settlements, err = Settlements("goa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the date is kept when not given
+	if updated := settlements[1]; updated.Id != later || updated.From != "walt" || !updated.Date.Equal(date.AddDate(0, 0, 1)) {
+		t.Errorf("got %+v", updated)
+	}
+
+	if err := DeleteSettlement("goa", earlier); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteSettlement("goa", earlier); err != ErrSettlementNotFound {
+		t.Errorf("expected ErrSettlementNotFound, got %v", err)
+	}
+	if err := DeleteSettlement("goa", later); err != nil {
+		t.Fatal(err)
+	}
+	if settlements, err := Settlements("goa"); err != nil || len(settlements) != 0 {
+		t.Errorf("got %+v, %v", settlements, err)
+	}
+}
@@ -3,49 +3,127 @@ package database
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/sankarvj/expensesplitter/pkg/money"
 )
 
+//ErrTransactionNotFound is returned when there is no transaction for the reference
+var ErrTransactionNotFound = errors.New("Transaction not found")
+
 //Trip ...
 type Trip struct {
+	Id           int64
 	Name         string
+	Currency     string `json:",omitempty"` // loaded by LoadTrip, the days don't store it
 	Transactions []Transaction
+	Settlements  []Settlement `json:",omitempty"` // loaded by LoadTrip, the days don't store them
 }
 
 //Transaction ...
 type Transaction struct {
-	Name   string
-	Amount float64
-	Shares []Share
+	Id       int64
+	Name     string
+	Amount   money.Money
+	Currency string `json:",omitempty"` // currency of the amounts when it is not the currency of the trip
+	Date     time.Time
+	Split    string // name of the split mode, empty for the transactions added before split modes
+	Shares   []Share
+	// line items of an itemised bill, the shares are derived from them
+	Items   []Item
+	Tax     money.Money
+	Service money.Money
+	Tip     money.Money
 }
 
 //Share ...
 type Share struct {
+	Id     int64
 	Member string
-	Amount float64
+	Amount money.Money
+	Paid   money.Money
+	Auto   bool  // share is split equally and gets recomputed when the transaction changes
+	Weight int64 // percent in basis points, units or adjustment in minor units as per the split mode of the transaction
+}
+
+//Item is a line item of an itemised transaction
+type Item struct {
+	Name     string
+	Price    money.Money
+	Quantity int64
+	Members  []string
 }
 
-//NewTrip ...
-func NewTrip(tripName, transactionName string, members []string, sharesSlice []float64) error {
-	var shares []Share
-	for i, member := range members {
-		share := Share{
-			Member: member,
-			Amount: sharesSlice[i],
+//NewTrip adds the transaction to the trip and returns the id generated for it.
+//Amount and date are filled if not given.
+func NewTrip(tripName string, transaction Transaction) (int64, error) {
+	if err := checkWritable(tripName); err != nil {
+		return 0, err
+	}
+
+	if transaction.Amount == 0 {
+		transaction.Amount = transaction.ShareTotal()
+	}
+	if transaction.Date.IsZero() {
+		transaction.Date = time.Now()
+	}
+	if err := assignIDs(tripName, &transaction); err != nil {
+		return 0, err
+	}
+
+	if err := addTransaction(tripName, transaction); err != nil {
+		return 0, err
+	}
+	return transaction.Id, addTripMembers(tripName, transaction.Members())
+}
+
+//UpdateTransaction replaces the transaction having the id.
+//The transaction moves to another day if its date is changed.
+func UpdateTransaction(tripName string, id int64, transaction Transaction) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+
+	key, day, index, err := locateTransaction(tripName, id)
+	if err != nil {
+		return err
+	}
+
+	transaction.Id = id
+	if err := assignIDs(tripName, &transaction); err != nil {
+		return err
+	}
+
+	newKey := dayKey(transaction.Date)
+	if newKey == key {
+		day.Transactions[index] = transaction
+		if err := storeNewTransactionData(day, key); err != nil {
+			return err
 		}
-		shares = append(shares, share)
+	} else if err := moveTransaction(day, key, index, newKey, transaction); err != nil {
+		return err
 	}
+	return addTripMembers(tripName, transaction.Members())
+}
 
-	transaction := Transaction{
-		Name:   transactionName,
-		Shares: shares,
+//DeleteTransaction removes the transaction having the id from its day
+func DeleteTransaction(tripName string, id int64) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
 	}
 
-	trip := &Trip{}
+	key, day, index, err := locateTransaction(tripName, id)
+	if err != nil {
+		return err
+	}
+	return removeTransaction(day, key, index)
+}
 
-	d := 24 * time.Hour
-	key := time.Now().Truncate(d).Format(time.RFC3339)
+// addTransaction appends the transaction to the day of its date
+func addTransaction(tripName string, transaction Transaction) error {
+	trip := &Trip{}
+	key := dayKey(transaction.Date)
 
 	result, err := retriveData(tripName, key)
 	if err != nil {
@@ -59,7 +137,6 @@ func NewTrip(tripName, transactionName string, members []string, sharesSlice []f
 			return err
 		}
 	} else {
-		fmt.Println("result --", result)
 		if result != "" {
 			err = json.Unmarshal([]byte(result), trip)
 			if err != nil {
@@ -67,19 +144,197 @@ func NewTrip(tripName, transactionName string, members []string, sharesSlice []f
 			}
 		} else {
 			trip = &Trip{
-				Name:         tripName,
-				Transactions: []Transaction{transaction},
+				Name: tripName,
 			}
 		}
-		if err := deDoupTransactionName(trip.Transactions, transaction.Name); err != nil {
-			return err
-		}
 		trip.Transactions = append(trip.Transactions, transaction)
 	}
 
 	return storeNewTransactionData(trip, key)
 }
 
+// moveTransaction replaces the transaction at index of the day by the transaction stored on the new day,
+// in a single transaction so that it is never kept on both days
+func moveTransaction(day *Trip, key string, index int, newKey string, transaction Transaction) error {
+	day.Transactions = append(day.Transactions[:index], day.Transactions[index+1:]...)
+	return updateData(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(day.Name))
+		if bucket == nil {
+			return ErrTransactionNotFound
+		}
+
+		newDay := &Trip{Name: day.Name}
+		if value := bucket.Get([]byte(newKey)); value != nil {
+			if err := json.Unmarshal(value, newDay); err != nil {
+				return err
+			}
+		}
+		newDay.Transactions = append(newDay.Transactions, transaction)
+		value, err := json.Marshal(newDay)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte(newKey), value); err != nil {
+			return err
+		}
+
+		if len(day.Transactions) == 0 {
+			return bucket.Delete([]byte(key))
+		}
+		value, err = json.Marshal(day)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(key), value)
+	})
+}
+
+// removeTransaction removes the transaction at index from the day. Empty days are deleted.
+func removeTransaction(day *Trip, key string, index int) error {
+	day.Transactions = append(day.Transactions[:index], day.Transactions[index+1:]...)
+	if len(day.Transactions) == 0 {
+		return deleteData(day.Name, key)
+	}
+	return storeNewTransactionData(day, key)
+}
+
+// locateTransaction finds the day holding the transaction having the id
+func locateTransaction(tripName string, id int64) (string, *Trip, int, error) {
+	var foundKey string
+	var foundDay *Trip
+	foundIndex := -1
+	err := forEachData(tripName, func(key, value []byte) error {
+		if foundDay != nil {
+			return nil
+		}
+		day, err := readDay(key, value)
+		if err != nil {
+			return err
+		}
+		for i, transaction := range day.Transactions {
+			if transaction.Id == id {
+				foundKey, foundDay, foundIndex = string(key), day, i
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errBucketNotFound {
+		return "", nil, -1, err
+	}
+	if foundDay == nil {
+		return "", nil, -1, ErrTransactionNotFound
+	}
+	foundDay.Name = tripName
+	return foundKey, foundDay, foundIndex, nil
+}
+
+// readDay decodes the transactions stored for a day and fills the fields missing in the older records
+func readDay(key, value []byte) (*Trip, error) {
+	day := &Trip{}
+	if err := json.Unmarshal(value, day); err != nil {
+		return nil, err
+	}
+	for i := range day.Transactions {
+		transaction := &day.Transactions[i]
+		if transaction.Date.IsZero() { // transactions stored before the date was recorded
+			transaction.Date, _ = time.Parse(time.RFC3339, string(key))
+		}
+		if transaction.Amount == 0 { // transactions stored before the amount was recorded
+			transaction.Amount = transaction.ShareTotal()
+		}
+		for j := range transaction.Shares { // names stored before they were normalised eg. " walt"
+			transaction.Shares[j].Member = NormaliseName(transaction.Shares[j].Member)
+		}
+	}
+	return day, nil
+}
+
+func checkWritable(tripName string) error {
+	info, err := GetTrip(tripName)
+	if err != nil {
+		return err
+	}
+	if info.Archived {
+		return ErrTripArchived
+	}
+	return nil
+}
+
+//LoadTrip reads the transactions of every day stored for the trip, oldest day first.
+//A trip without any transaction is returned empty.
+func LoadTrip(tripName string) (*Trip, error) {
+	info, err := GetTrip(tripName)
+	if err != nil {
+		return nil, err
+	}
+	trip := &Trip{
+		Id:       info.Id,
+		Name:     tripName,
+		Currency: info.Currency,
+	}
+
+	err = forEachData(tripName, func(key, value []byte) error {
+		day, err := readDay(key, value)
+		if err != nil {
+			return err
+		}
+		trip.Transactions = append(trip.Transactions, day.Transactions...)
+		return nil
+	})
+	if err != nil && err != errBucketNotFound {
+		return nil, err
+	}
+
+	if trip.Settlements, err = Settlements(tripName); err != nil {
+		return nil, err
+	}
+	return trip, nil
+}
+
+//FindTransaction finds the transaction of the trip by its id
+func FindTransaction(tripName string, id int64) (*Transaction, error) {
+	_, day, index, err := locateTransaction(tripName, id)
+	if err != nil {
+		return nil, err
+	}
+	return &day.Transactions[index], nil
+}
+
+//Members returns the names of the members sharing or paying for the transaction
+func (transaction Transaction) Members() []string {
+	members := make([]string, len(transaction.Shares))
+	for i, share := range transaction.Shares {
+		members[i] = share.Member
+	}
+	return members
+}
+
+//ShareTotal is the sum of the shares of the transaction
+func (transaction Transaction) ShareTotal() money.Money {
+	var total money.Money
+	for _, share := range transaction.Shares {
+		total = total + share.Amount
+	}
+	return total
+}
+
+//Payers returns the shares of the members who paid for the transaction
+func (transaction Transaction) Payers() []Share {
+	payers := make([]Share, 0)
+	for _, share := range transaction.Shares {
+		if share.Paid != 0 {
+			payers = append(payers, share)
+		}
+	}
+	return payers
+}
+
+// dayKey is the key under which the transactions of the day are stored, the midnight of the local date
+func dayKey(t time.Time) string {
+	return startOfDay(t).Format(time.RFC3339)
+}
+
 func storeNewTransactionData(trip *Trip, key string) error {
 	json, err := json.Marshal(trip)
 	if err != nil {
@@ -88,10 +343,33 @@ func storeNewTransactionData(trip *Trip, key string) error {
 	return storeData(trip.Name, key, json)
 }
 
-func deDoupTransactionName(transactions []Transaction, transactionName string) error {
-	for _, transaction := range transactions {
-		if transaction.Name == transactionName {
-			return errors.New("Could not have duplicate transaction on the same day")
+// assignIDs generates the ids missing in the transaction and its shares.
+// Transaction ids are drawn from the sequence of the trip and share ids from a sequence shared by all trips.
+func assignIDs(tripName string, transaction *Transaction) error {
+	if transaction.Id == 0 {
+		ids, err := nextSequences(tripName, 1)
+		if err != nil {
+			return err
+		}
+		transaction.Id = ids[0]
+	}
+
+	count := 0
+	for _, share := range transaction.Shares {
+		if share.Id == 0 {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	ids, err := nextSequences(sharesBucketName, count)
+	if err != nil {
+		return err
+	}
+	for i := range transaction.Shares {
+		if transaction.Shares[i].Id == 0 {
+			transaction.Shares[i].Id, ids = ids[0], ids[1:]
 		}
 	}
 	return nil
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+// inTempDir runs the test against an empty store, the store is kept in the working directory
+func inTempDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "expense")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	migrated = false // every store is migrated on its first open
+	return func() {
+		os.Chdir(wd)
+		os.RemoveAll(dir)
+	}
+}
+
+// dayKeys lists the days stored for the trip
+func dayKeys(t *testing.T, tripName string) []string {
+	t.Helper()
+	keys := make([]string, 0)
+	err := forEachData(tripName, func(key, value []byte) error {
+		keys = append(keys, string(key))
+		return nil
+	})
+	if err != nil && err != errBucketNotFound {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func testTransaction(name string, date time.Time) Transaction {
+	return Transaction{
+		Name: name,
+		Date: date,
+		Shares: []Share{
+			{Member: "walt", Amount: money.FromMinor(1500), Paid: money.FromMinor(3000)},
+			{Member: "jesse", Amount: money.FromMinor(1500)},
+		},
+	}
+}
+
+func TestUpdateTransactionMovesDay(t *testing.T) {
+	defer inTempDir(t)()
+	if err := CreateTrip("goa", nil, "EUR", ""); err != nil {
+		t.Fatal(err)
+	}
+	first := time.Date(2026, 1, 30, 20, 0, 0, 0, time.Local)
+	second := time.Date(2026, 1, 31, 9, 0, 0, 0, time.Local)
+	taxi, err := NewTrip("goa", testTransaction("taxi", first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTrip("goa", testTransaction("dinner", first)); err != nil {
+		t.Fatal(err)
+	}
+
+	moved := testTransaction("taxi", second)
+	if err := UpdateTransaction("goa", taxi, moved); err != nil {
+		t.Fatal(err)
+	}
+	if keys := dayKeys(t, "goa"); len(keys) != 2 || keys[0] != dayKey(first) || keys[1] != dayKey(second) {
+		t.Errorf("got days %v", keys)
+	}
+	trip, err := LoadTrip("goa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trip.Transactions) != 2 || trip.Transactions[0].Name != "dinner" || trip.Transactions[1].Id != taxi || !trip.Transactions[1].Date.Equal(second) {
+		t.Errorf("got %+v", trip.Transactions)
+	}
+
+	// moving the last transaction of the day deletes the day
+	moved.Date = first.AddDate(0, 0, 5)
+	if err := UpdateTransaction("goa", taxi, moved); err != nil {
+		t.Fatal(err)
+	}
+	if keys := dayKeys(t, "goa"); len(keys) != 2 || keys[1] != dayKey(moved.Date) {
+		t.Errorf("got days %v", keys)
+	}
+	found, err := FindTransaction("goa", taxi)
+	if err != nil || !found.Date.Equal(moved.Date) {
+		t.Errorf("got %+v, %v", found, err)
+	}
+}
+
+func TestDayKeyLocalDate(t *testing.T) {
+	local := time.Local
+	defer func() { time.Local = local }()
+	time.Local = time.FixedZone("IST", 5*60*60+30*60)
+
+	// 01:00 on the 31st in India is still the 30th in UTC
+	date := time.Date(2026, 1, 31, 1, 0, 0, 0, time.Local)
+	if got := dayKey(date); got != "2026-01-31T00:00:00+05:30" {
+		t.Errorf("got %s", got)
+	}
+	if got := dayKey(date.UTC()); got != "2026-01-31T00:00:00+05:30" {
+		t.Errorf("got %s for the UTC time", got)
+	}
+}
@@ -0,0 +1,348 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+//DefaultTrip is used when no trip has been created or chosen
+const DefaultTrip = defaultBucketName
+
+const currentTripKey = "currenttrip"
+
+var (
+	//ErrTripNotFound is returned when the trip is not created yet
+	ErrTripNotFound = errors.New("Trip not found")
+	//ErrTripExists is returned when a trip with the same name is already created
+	ErrTripExists = errors.New("Trip already exists")
+	//ErrTripArchived is returned when a transaction is added to an archived trip
+	ErrTripArchived = errors.New("Trip is archived")
+	//ErrArchiveDefaultTrip is returned when archiving the default trip
+	ErrArchiveDefaultTrip = errors.New("Default trip can't be archived")
+	//ErrInvalidTripName is returned for empty or reserved trip names
+	ErrInvalidTripName = errors.New("Trip name should not be empty or start with _")
+)
+
+//TripInfo is the metadata kept for each trip alongside its transactions
+type TripInfo struct {
+	Id        int64
+	Name      string
+	Members   []string
+	Currency  string
+	Remainder string // name of the remainder policy used when splitting equally
+	Created   time.Time
+	Archived  bool
+}
+
+//CreateTrip creates the metadata of a new trip
+func CreateTrip(tripName string, members []string, currency string, remainder string) error {
+	if err := validateTripName(tripName); err != nil {
+		return err
+	}
+	if _, err := GetTrip(tripName); err != ErrTripNotFound {
+		if err == nil {
+			return ErrTripExists
+		}
+		return err
+	}
+
+	info := &TripInfo{
+		Name:      tripName,
+		Members:   addMembers(nil, members),
+		Currency:  strings.ToUpper(currency),
+		Remainder: remainder,
+		Created:   time.Now(),
+	}
+	return storeTripInfo(info)
+}
+
+//GetTrip returns the metadata of the trip. The default trip always exists.
+func GetTrip(tripName string) (*TripInfo, error) {
+	result, err := retriveData(tripsBucketName, tripName)
+	if err != nil && err != errBucketNotFound {
+		return nil, err
+	}
+	if result == "" {
+		if tripName == DefaultTrip {
+			return &TripInfo{Name: DefaultTrip}, nil
+		}
+		return nil, ErrTripNotFound
+	}
+
+	info := &TripInfo{}
+	if err := json.Unmarshal([]byte(result), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+//Trips lists the metadata of all the trips ordered by the creation date
+func Trips() ([]TripInfo, error) {
+	trips := make([]TripInfo, 0)
+	err := forEachData(tripsBucketName, func(key, value []byte) error {
+		info := TripInfo{}
+		if err := json.Unmarshal(value, &info); err != nil {
+			return err
+		}
+		trips = append(trips, info)
+		return nil
+	})
+	if err != nil && err != errBucketNotFound {
+		return nil, err
+	}
+	sort.SliceStable(trips, func(i, j int) bool { return trips[i].Created.Before(trips[j].Created) })
+	return trips, nil
+}
+
+//ResolveTrip returns the name of the trip given either its name or its id
+func ResolveTrip(nameOrID string) (string, error) {
+	if _, err := GetTrip(nameOrID); err != ErrTripNotFound {
+		return nameOrID, err
+	}
+	id, err := strconv.ParseInt(nameOrID, 10, 64)
+	if err != nil {
+		return nameOrID, nil
+	}
+
+	trips, err := Trips()
+	if err != nil {
+		return "", err
+	}
+	for _, trip := range trips {
+		if trip.Id == id {
+			return trip.Name, nil
+		}
+	}
+	return nameOrID, nil
+}
+
+//CurrentTrip returns the trip chosen with UseTrip or the default trip
+func CurrentTrip() (string, error) {
+	result, err := retriveData(settingsBucketName, currentTripKey)
+	if err != nil && err != errBucketNotFound {
+		return "", err
+	}
+	if result == "" {
+		return DefaultTrip, nil
+	}
+	return result, nil
+}
+
+//UseTrip persists the trip as the current trip
+func UseTrip(tripName string) error {
+	info, err := GetTrip(tripName)
+	if err != nil {
+		return err
+	}
+	if info.Archived {
+		return ErrTripArchived
+	}
+	return storeData(settingsBucketName, currentTripKey, []byte(tripName))
+}
+
+//ArchiveTrip marks the trip as archived. Archived trips can be read but not changed.
+//The default trip is never archived as it is used when no trip is chosen.
+func ArchiveTrip(tripName string) error {
+	if tripName == DefaultTrip {
+		return ErrArchiveDefaultTrip
+	}
+	info, err := GetTrip(tripName)
+	if err != nil {
+		return err
+	}
+	info.Archived = true
+	if err := storeTripInfo(info); err != nil {
+		return err
+	}
+
+	current, err := CurrentTrip()
+	if err != nil {
+		return err
+	}
+	if current == tripName {
+		return storeData(settingsBucketName, currentTripKey, []byte(DefaultTrip))
+	}
+	return nil
+}
+
+//RenameTrip renames the trip along with its stored transactions
+func RenameTrip(oldName, newName string) error {
+	if err := validateTripName(newName); err != nil {
+		return err
+	}
+	info, err := GetTrip(oldName)
+	if err != nil {
+		return err
+	}
+	if _, err := GetTrip(newName); err != ErrTripNotFound {
+		if err == nil {
+			return ErrTripExists
+		}
+		return err
+	}
+
+	info.Name = newName
+	return updateData(func(tx *bolt.Tx) error {
+		trips, err := tx.CreateBucketIfNotExists([]byte(tripsBucketName))
+		if err != nil {
+			return err
+		}
+		if trips.Get([]byte(newName)) != nil {
+			return ErrTripExists
+		}
+
+		err = renameBucket(tx, oldName, newName, func(value []byte) ([]byte, error) {
+			trip := &Trip{}
+			if err := json.Unmarshal(value, trip); err != nil {
+				return nil, err
+			}
+			trip.Name = newName
+			return json.Marshal(trip)
+		})
+		if err != nil {
+			if err == errBucketExists {
+				return ErrTripExists
+			}
+			return err
+		}
+
+		// settlements, members and reminders are kept under the trip name in their own buckets
+		for _, bucketName := range []string{settlementsBucketName, membersBucketName, remindersBucketName, reminderLogBucketName} {
+			if err := moveData(tx, bucketName, oldName, newName); err != nil {
+				return err
+			}
+		}
+
+		if info.Id == 0 { // the default trip has no metadata until its first transaction
+			id, err := trips.NextSequence()
+			if err != nil {
+				return err
+			}
+			info.Id = int64(id)
+		}
+		infoJSON, err := json.Marshal(info)
+		if err != nil {
+			return err
+		}
+		if err := trips.Put([]byte(newName), infoJSON); err != nil {
+			return err
+		}
+		if err := trips.Delete([]byte(oldName)); err != nil {
+			return err
+		}
+
+		settings, err := tx.CreateBucketIfNotExists([]byte(settingsBucketName))
+		if err != nil {
+			return err
+		}
+		current := string(settings.Get([]byte(currentTripKey)))
+		if current == "" {
+			current = DefaultTrip
+		}
+		if current == oldName {
+			return settings.Put([]byte(currentTripKey), []byte(newName))
+		}
+		return nil
+	})
+}
+
+//ClearTrip deletes every transaction and settlement of the trip, keeping the trip and its members
+func ClearTrip(tripName string) error {
+	if err := checkWritable(tripName); err != nil {
+		return err
+	}
+	return updateData(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(tripName)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		if settlements := tx.Bucket([]byte(settlementsBucketName)); settlements != nil {
+			return settlements.Delete([]byte(tripName))
+		}
+		return nil
+	})
+}
+
+//DeleteTrip deletes the trip along with its transactions, settlements, members and reminders
+func DeleteTrip(tripName string) error {
+	if _, err := GetTrip(tripName); err != nil {
+		return err
+	}
+	if err := DeleteBucket(tripName); err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	for _, bucketName := range []string{settlementsBucketName, membersBucketName, remindersBucketName, reminderLogBucketName, tripsBucketName} {
+		if err := deleteData(bucketName, tripName); err != nil {
+			return err
+		}
+	}
+
+	current, err := CurrentTrip()
+	if err != nil {
+		return err
+	}
+	if current == tripName {
+		return storeData(settingsBucketName, currentTripKey, []byte(DefaultTrip))
+	}
+	return nil
+}
+
+// addTripMembers keeps the members of the trip metadata upto date with its transactions
+func addTripMembers(tripName string, members []string) error {
+	info, err := GetTrip(tripName)
+	if err != nil {
+		return err
+	}
+	if info.Created.IsZero() { // the default trip is created on its first transaction
+		info.Created = time.Now()
+	}
+	info.Members = addMembers(info.Members, members)
+	return storeTripInfo(info)
+}
+
+func addMembers(existing []string, members []string) []string {
+	for _, member := range members {
+		member = NormaliseName(member)
+		if member == "" {
+			continue
+		}
+		found := false
+		for _, existingMember := range existing {
+			if existingMember == member {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, member)
+		}
+	}
+	return existing
+}
+
+func storeTripInfo(info *TripInfo) error {
+	if info.Id == 0 {
+		ids, err := nextSequences(tripsBucketName, 1)
+		if err != nil {
+			return err
+		}
+		info.Id = ids[0]
+	}
+	json, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return storeData(tripsBucketName, info.Name, json)
+}
+
+func validateTripName(tripName string) error {
+	if strings.TrimSpace(tripName) == "" || strings.HasPrefix(tripName, "_") {
+		return ErrInvalidTripName
+	}
+	return nil
+}
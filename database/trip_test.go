@@ -0,0 +1,146 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+func TestRenameTrip(t *testing.T) {
+	defer inTempDir(t)()
+	if err := CreateTrip("goa", []string{"walt", "jesse"}, "EUR", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseTrip("goa"); err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 1, 30, 20, 0, 0, 0, time.Local)
+	taxi, err := NewTrip("goa", testTransaction("taxi", date))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddSettlement("goa", Settlement{From: "jesse", To: "walt", Amount: money.FromMinor(1500), Date: date}); err != nil {
+		t.Fatal(err)
+	}
+	before, err := GetTrip("goa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RenameTrip("goa", "manali"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetTrip("goa"); err != ErrTripNotFound {
+		t.Errorf("expected the old name gone, got %v", err)
+	}
+	info, err := GetTrip("manali")
+	if err != nil || info.Id != before.Id || info.Name != "manali" {
+		t.Errorf("got trip %+v, %v", info, err)
+	}
+	if current, err := CurrentTrip(); err != nil || current != "manali" {
+		t.Errorf("expected the current trip to follow the rename, got %s, %v", current, err)
+	}
+	trip, err := LoadTrip("manali")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trip.Transactions) != 1 || trip.Transactions[0].Id != taxi || len(trip.Settlements) != 1 {
+		t.Errorf("got %+v", trip)
+	}
+	members, err := Members("manali")
+	if err != nil || len(members) != 2 {
+		t.Errorf("got members %+v, %v", members, err)
+	}
+
+	// the ids continue after the rename
+	dinner, err := NewTrip("manali", testTransaction("dinner", date))
+	if err != nil || dinner <= taxi {
+		t.Errorf("got id %d after %d, %v", dinner, taxi, err)
+	}
+
+	if err := CreateTrip("goa", nil, "EUR", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := RenameTrip("goa", "manali"); err != ErrTripExists {
+		t.Errorf("expected ErrTripExists, got %v", err)
+	}
+	if err := RenameTrip("goa", "_settings"); err != ErrInvalidTripName {
+		t.Errorf("expected ErrInvalidTripName, got %v", err)
+	}
+}
+
+func TestArchiveTrip(t *testing.T) {
+	defer inTempDir(t)()
+	if err := ArchiveTrip(DefaultTrip); err != ErrArchiveDefaultTrip {
+		t.Errorf("expected ErrArchiveDefaultTrip, got %v", err)
+	}
+	if err := CreateTrip("goa", []string{"walt", "jesse"}, "EUR", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseTrip("goa"); err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 1, 30, 20, 0, 0, 0, time.Local)
+	if _, err := NewTrip("goa", testTransaction("taxi", date)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ArchiveTrip("goa"); err != nil {
+		t.Fatal(err)
+	}
+	if current, err := CurrentTrip(); err != nil || current != DefaultTrip {
+		t.Errorf("expected the default trip to be current, got %s, %v", current, err)
+	}
+	if _, err := NewTrip("goa", testTransaction("dinner", date)); err != ErrTripArchived {
+		t.Errorf("expected ErrTripArchived, got %v", err)
+	}
+	if _, err := AddSettlement("goa", Settlement{From: "jesse", To: "walt", Amount: money.FromMinor(1500)}); err != ErrTripArchived {
+		t.Errorf("expected ErrTripArchived, got %v", err)
+	}
+	if err := UseTrip("goa"); err != ErrTripArchived {
+		t.Errorf("expected ErrTripArchived, got %v", err)
+	}
+	// archived trips are still read
+	trip, err := LoadTrip("goa")
+	if err != nil || len(trip.Transactions) != 1 {
+		t.Errorf("got %+v, %v", trip, err)
+	}
+}
+
+func TestClearTrip(t *testing.T) {
+	defer inTempDir(t)()
+	if err := CreateTrip("goa", []string{"walt", "jesse"}, "EUR", ""); err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 1, 30, 20, 0, 0, 0, time.Local)
+	if _, err := NewTrip("goa", testTransaction("taxi", date)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddSettlement("goa", Settlement{From: "jesse", To: "walt", Amount: money.FromMinor(1500), Date: date}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ClearTrip("goa"); err != nil {
+		t.Fatal(err)
+	}
+	trip, err := LoadTrip("goa")
+	if err != nil || len(trip.Transactions) != 0 || len(trip.Settlements) != 0 {
+		t.Errorf("got %+v, %v", trip, err)
+	}
+	members, err := Members("goa")
+	if err != nil || len(members) != 2 {
+		t.Errorf("expected the members kept, got %+v, %v", members, err)
+	}
+	// clearing an empty trip is fine
+	if err := ClearTrip("goa"); err != nil {
+		t.Errorf("got %v", err)
+	}
+
+	if err := ArchiveTrip("goa"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClearTrip("goa"); err != ErrTripArchived {
+		t.Errorf("expected ErrTripArchived, got %v", err)
+	}
+}
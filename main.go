@@ -25,5 +25,13 @@ func commands() []cli.Command {
 	return []cli.Command{
 		cmd.TransactionCmd(),
 		cmd.SuggestCmd(),
+		cmd.TripCmd(),
+		cmd.SettleCmd(),
+		cmd.BalanceCmd(),
+		cmd.MemberCmd(),
+		cmd.RatesCmd(),
+		cmd.RemindCmd(),
+		cmd.NotifyCmd(),
+		cmd.ServeCmd(),
 	}
 }
@@ -0,0 +1,162 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//ErrNoRecipients is returned when the message has nobody to send it to
+var ErrNoRecipients = errors.New("Message has no recipients")
+
+//Message is a plain text email, sent along with its HTML version when it has one
+type Message struct {
+	From    string
+	To      []string
+	Cc      []string // copied in, sent along with To (Optional)
+	Subject string
+	Body    string
+	HTML    string    // HTML version of the body (Optional)
+	Date    time.Time // time of sending if not given
+}
+
+//Bytes writes the message in the internet message format understood by the SMTP servers and the mail clients
+func (message *Message) Bytes() []byte {
+	date := message.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var buffer bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buffer, "%s: %s\r\n", name, value)
+	}
+	header("From", message.From)
+	header("To", strings.Join(message.To, ", "))
+	if len(message.Cc) > 0 {
+		header("Cc", strings.Join(message.Cc, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	if message.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "8bit")
+		buffer.WriteString("\r\n")
+		buffer.WriteString(crlf(message.Body))
+		return buffer.Bytes()
+	}
+
+	parts := multipart.NewWriter(&buffer)
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%s", parts.Boundary()))
+	buffer.WriteString("\r\n")
+	// mail clients show the last part they understand, so the HTML version goes last
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", message.Body},
+		{"text/html; charset=utf-8", message.HTML},
+	} {
+		writer, _ := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		encoder := quotedprintable.NewWriter(writer)
+		encoder.Write([]byte(crlf(part.content)))
+		encoder.Close()
+	}
+	parts.Close()
+	return buffer.Bytes()
+}
+
+// crlf ends the lines of the text with CRLF as per RFC 5322
+func crlf(text string) string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	if !strings.HasSuffix(text, "\n") {
+		text = text + "\n"
+	}
+	return strings.Replace(text, "\n", "\r\n", -1)
+}
+
+//Sender delivers the messages
+type Sender interface {
+	Send(message *Message) error
+}
+
+//SMTP sends the messages through the SMTP server. The server is used without authentication when
+//the username is empty, like the local stand-ins such as MailHog or python -m smtpd.
+type SMTP struct {
+	Addr     string // host:port of the server
+	Username string
+	Password string
+}
+
+//Send delivers the message to each of its recipients, those copied in included
+func (server *SMTP) Send(message *Message) error {
+	if len(message.To) == 0 {
+		return ErrNoRecipients
+	}
+	from, err := mail.ParseAddress(message.From)
+	if err != nil {
+		return err
+	}
+	recipients := append(append([]string{}, message.To...), message.Cc...)
+	to := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return err
+		}
+		to[i] = address.Address
+	}
+
+	var auth smtp.Auth
+	if server.Username != "" {
+		host := server.Addr
+		if i := strings.LastIndex(host, ":"); i != -1 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", server.Username, server.Password, host)
+	}
+	return smtp.SendMail(server.Addr, auth, from.Address, to, message.Bytes())
+}
+
+//Dir writes each message as an .eml file in the directory instead of sending it
+type Dir struct {
+	Path string
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9@._-]+`)
+
+//Send writes the message to a file named after its first recipient, replacing the file written before
+func (dir *Dir) Send(message *Message) error {
+	if len(message.To) == 0 {
+		return ErrNoRecipients
+	}
+	if err := os.MkdirAll(dir.Path, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dir.File(message), message.Bytes(), 0644)
+}
+
+//File is the path of the file the message is written to
+func (dir *Dir) File(message *Message) string {
+	name := ""
+	if len(message.To) > 0 {
+		name = message.To[0]
+		if address, err := mail.ParseAddress(name); err == nil {
+			name = address.Address
+		}
+	}
+	return filepath.Join(dir.Path, unsafeName.ReplaceAllString(name, "_")+".eml")
+}
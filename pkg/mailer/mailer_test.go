@@ -0,0 +1,174 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMessage() *Message {
+	return &Message{
+		From:    "Expense Splitter <splitter@example.com>",
+		To:      []string{"Walt <walt@example.com>"},
+		Subject: "You owe gus 38.99 €",
+		Body:    "Hi walt,\n\nYou have to give 38.99 to gus.\n",
+		Date:    time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestMessageBytes(t *testing.T) {
+	got := string(testMessage().Bytes())
+	for _, want := range []string{
+		"From: Expense Splitter <splitter@example.com>\r\n",
+		"To: Walt <walt@example.com>\r\n",
+		"Subject: =?utf-8?q?You_owe_gus_38.99_=E2=82=AC?=\r\n",
+		"Date: Mon, 02 Mar 2026 10:00:00 +0000\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\r\n\r\nHi walt,\r\n\r\nYou have to give 38.99 to gus.\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message doesn't have %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestMessageBytesWithHTML(t *testing.T) {
+	message := testMessage()
+	message.HTML = "<p>You have to give <b>38.99 €</b> to gus.</p>"
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(message.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("got content type %q, %v", mediaType, err)
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, want := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", "Hi walt,\r\n\r\nYou have to give 38.99 to gus.\r\n"},
+		{"text/html; charset=utf-8", "<p>You have to give <b>38.99 €</b> to gus.</p>\r\n"},
+	} {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the multipart reader decodes the quoted-printable content
+		content, _ := ioutil.ReadAll(part)
+		if part.Header.Get("Content-Type") != want.contentType || string(content) != want.content {
+			t.Errorf("got %q %q, want %q %q", part.Header.Get("Content-Type"), content, want.contentType, want.content)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("expected two parts, got %v", err)
+	}
+}
+
+// fakeSMTP accepts a single message the way a local SMTP stand-in does and returns its envelope and data
+func fakeSMTP(t *testing.T) (string, <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan []string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		lines := make([]string, 0)
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ready")
+		data := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case data && line == ".":
+				data = false
+				reply("250 queued")
+			case data:
+				lines = append(lines, line)
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(line, "MAIL"), strings.HasPrefix(line, "RCPT"):
+				lines = append(lines, line)
+				reply("250 ok")
+			case line == "DATA":
+				data = true
+				reply("354 go ahead")
+			case line == "QUIT":
+				reply("221 bye")
+				received <- lines
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+		received <- lines
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSMTPSend(t *testing.T) {
+	addr, received := fakeSMTP(t)
+	server := &SMTP{Addr: addr}
+	message := testMessage()
+	message.Cc = []string{`"Gus" <gus@example.com>`}
+	if err := server.Send(message); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := <-received
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{"MAIL FROM:<splitter@example.com>", "RCPT TO:<walt@example.com>", "RCPT TO:<gus@example.com>", "To: Walt <walt@example.com>", `Cc: "Gus" <gus@example.com>`, "You have to give 38.99 to gus."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("server didn't get %q:\n%s", want, got)
+		}
+	}
+
+	if err := server.Send(&Message{From: "splitter@example.com"}); err != ErrNoRecipients {
+		t.Errorf("expected ErrNoRecipients, got %v", err)
+	}
+}
+
+func TestDirSend(t *testing.T) {
+	path, err := ioutil.TempDir("", "mailer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	dir := &Dir{Path: filepath.Join(path, "reminders")}
+	message := testMessage()
+	if err := dir.Send(message); err != nil {
+		t.Fatal(err)
+	}
+	if got := dir.File(message); got != filepath.Join(path, "reminders", "walt@example.com.eml") {
+		t.Errorf("file is %s", got)
+	}
+	written, err := ioutil.ReadFile(dir.File(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != string(message.Bytes()) {
+		t.Errorf("file has\n%s", written)
+	}
+}
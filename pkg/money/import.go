@@ -0,0 +1,92 @@
+package money
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+//ECBBase is the base currency of the reference rates published by the European Central Bank
+const ECBBase = "EUR"
+
+//ErrNoRates is returned when the file has no rates in it
+var ErrNoRates = errors.New("No rates found in the file")
+
+// ecbEnvelope is the eurofxref-daily and eurofxref-hist XML of the European Central Bank
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+//ReadECB reads the rates of the eurofxref XML files published by the European Central Bank.
+//Every rate has EUR as its base.
+func ReadECB(r io.Reader) ([]Rate, error) {
+	envelope := ecbEnvelope{}
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, err
+	}
+
+	rates := make([]Rate, 0)
+	for _, day := range envelope.Days {
+		date, err := time.ParseInLocation("2006-01-02", day.Time, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid date %q in the rates", day.Time)
+		}
+		for _, rate := range day.Rates {
+			if _, err := ParseRate(rate.Rate); err != nil {
+				return nil, fmt.Errorf("Invalid rate %q for %s on %s", rate.Rate, rate.Currency, day.Time)
+			}
+			rates = append(rates, Rate{Base: ECBBase, Quote: normaliseCurrency(rate.Currency), Date: date, Value: rate.Rate})
+		}
+	}
+	if len(rates) == 0 {
+		return nil, ErrNoRates
+	}
+	return rates, nil
+}
+
+//ReadRatesCSV reads the rates of a CSV file having date, base, quote and rate in each row eg. 2026-01-31,EUR,USD,1.08.
+//The header row is skipped if there is one.
+func ReadRatesCSV(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	rates := make([]Rate, 0)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(record[0]), time.Local)
+		if err != nil {
+			if line == 1 { // header
+				continue
+			}
+			return nil, fmt.Errorf("Invalid date %q on line %d", record[0], line)
+		}
+		rate := Rate{Base: normaliseCurrency(record[1]), Quote: normaliseCurrency(record[2]), Date: date, Value: strings.TrimSpace(record[3])}
+		if _, err := NewRates([]Rate{rate}); err != nil {
+			return nil, fmt.Errorf("Invalid rate on line %d: %s", line, err.Error())
+		}
+		rates = append(rates, rate)
+	}
+	if len(rates) == 0 {
+		return nil, ErrNoRates
+	}
+	return rates, nil
+}
@@ -0,0 +1,64 @@
+package money
+
+import (
+	"strings"
+	"testing"
+)
+
+const ecbHist = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2026-01-30">
+			<Cube currency="USD" rate="1.0812"/>
+			<Cube currency="JPY" rate="162.5"/>
+		</Cube>
+		<Cube time="2026-01-29">
+			<Cube currency="USD" rate="1.0790"/>
+			<Cube currency="INR" rate="90.12"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestReadECB(t *testing.T) {
+	rates, err := ReadECB(strings.NewReader(ecbHist))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 4 {
+		t.Fatalf("got %d rates, want 4", len(rates))
+	}
+	got := rates[1]
+	if got.Base != "EUR" || got.Quote != "JPY" || got.Value != "162.5" || got.Date.Format("2006-01-02") != "2026-01-30" {
+		t.Errorf("got %+v", got)
+	}
+
+	if _, err := ReadECB(strings.NewReader(`<Envelope><Cube></Cube></Envelope>`)); err != ErrNoRates {
+		t.Errorf("expected ErrNoRates, got %v", err)
+	}
+}
+
+func TestReadRatesCSV(t *testing.T) {
+	rates, err := ReadRatesCSV(strings.NewReader("date,base,quote,rate\n2026-01-30, usd, inr, 83.2\n# comment\n2026-01-31,GBP,USD,1.27\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 2 || rates[0].Base != "USD" || rates[0].Quote != "INR" || rates[0].Value != "83.2" || rates[1].Base != "GBP" {
+		t.Errorf("got %+v", rates)
+	}
+
+	cases := []string{
+		"2026-01-30,USD,INR,abc\n",
+		"2026-01-30,USD,INR\n",
+		"2026-01-30,USD,INR,83.2\n30/01/2026,USD,INR,83.2\n",
+		"date,base,quote,rate\n",
+	}
+	for _, c := range cases {
+		if _, err := ReadRatesCSV(strings.NewReader(c)); err == nil {
+			t.Errorf("ReadRatesCSV(%q) should fail", c)
+		}
+	}
+}
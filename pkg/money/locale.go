@@ -0,0 +1,106 @@
+package money
+
+import (
+	"strings"
+)
+
+//Locale is the way a language writes the amounts
+type Locale struct {
+	Decimal     string
+	Group       string
+	Grouping    []int  // sizes of the digit groups from the right, the last one repeats eg. 3 or 3, 2 for lakhs
+	MinGrouping int    // digits the integer part needs beyond the first group before it is grouped
+	Pattern     string // where the symbol goes around the number #
+}
+
+// nbsp keeps the symbol on the same line as the number
+const nbsp = "\u00a0"
+
+// locales follow the CLDR data of each language
+var locales = map[string]Locale{
+	"en": {Decimal: ".", Group: ",", Grouping: []int{3}, MinGrouping: 1, Pattern: "¤#"},
+	"es": {Decimal: ",", Group: ".", Grouping: []int{3}, MinGrouping: 2, Pattern: "#" + nbsp + "¤"},
+	"de": {Decimal: ",", Group: ".", Grouping: []int{3}, MinGrouping: 1, Pattern: "#" + nbsp + "¤"},
+	"ta": {Decimal: ".", Group: ",", Grouping: []int{3, 2}, MinGrouping: 1, Pattern: "¤" + nbsp + "#"},
+}
+
+// symbols of the common currencies. Others are written with their code.
+var symbols = map[string]string{
+	"EUR": "€", "USD": "$", "GBP": "£", "INR": "₹", "JPY": "¥", "CNY": "¥", "KRW": "₩",
+	"AUD": "A$", "CAD": "CA$", "CHF": "CHF", "MXN": "MX$", "BRL": "R$", "LKR": "Rs", "SGD": "S$",
+}
+
+//LocaleOf returns the locale of the language given as en, de-DE or ta_IN. English is used for unknown languages.
+func LocaleOf(language string) Locale {
+	if locale, ok := locales[BaseLanguage(language)]; ok {
+		return locale
+	}
+	return locales["en"]
+}
+
+//FormatLocale writes the amount with the separators of the language and the symbol of the currency eg. €1,234.50 or 1.234,50 €.
+//The symbol is left out when the currency is empty.
+func (m Money) FormatLocale(currency string, language string) string {
+	locale := LocaleOf(language)
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	exponent := Exponent(currency)
+
+	plain := format(m, exponent)
+	sign := ""
+	if strings.HasPrefix(plain, "-") {
+		sign, plain = "-", plain[1:]
+	}
+	integer, fraction := plain, ""
+	if exponent > 0 {
+		integer, fraction = plain[:len(plain)-exponent-1], plain[len(plain)-exponent:]
+	}
+	number := locale.group(integer)
+	if fraction != "" {
+		number = number + locale.Decimal + fraction
+	}
+	if currency == "" {
+		return sign + number
+	}
+
+	symbol, ok := symbols[currency]
+	pattern := locale.Pattern
+	if !ok {
+		// codes read better apart from the number
+		symbol = currency
+		pattern = strings.Replace(pattern, "¤#", "¤"+nbsp+"#", 1)
+	}
+	return sign + strings.Replace(strings.Replace(pattern, "#", number, 1), "¤", symbol, 1)
+}
+
+// group separates the digits of the integer part in groups
+func (locale Locale) group(digits string) string {
+	if len(locale.Grouping) == 0 || len(digits) < locale.Grouping[0]+locale.MinGrouping {
+		return digits
+	}
+	groups := make([]string, 0)
+	for i := 0; len(digits) > 0; i++ {
+		size := locale.Grouping[len(locale.Grouping)-1]
+		if i < len(locale.Grouping) {
+			size = locale.Grouping[i]
+		}
+		if size >= len(digits) {
+			groups = append(groups, digits)
+			break
+		}
+		groups = append(groups, digits[len(digits)-size:])
+		digits = digits[:len(digits)-size]
+	}
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+	return strings.Join(groups, locale.Group)
+}
+
+//BaseLanguage returns the language of the tag given as es, de-DE or ta_IN, eg. de for de-DE
+func BaseLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i != -1 {
+		tag = tag[:i]
+	}
+	return tag
+}
@@ -0,0 +1,37 @@
+package money
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatLocale(t *testing.T) {
+	cases := []struct {
+		amount   Money
+		currency string
+		language string
+		want     string
+	}{
+		{123456789, "EUR", "en", "€1,234,567.89"},
+		{123456789, "EUR", "de", "1.234.567,89 €"},
+		{123456789, "EUR", "es", "1.234.567,89 €"},
+		{123456, "EUR", "es", "1234,56 €"}, // Spanish groups from five digits
+		{1234567, "EUR", "es", "12.345,67 €"},
+		{1000000000, "INR", "ta", "₹ 1,00,00,000.00"},
+		{123456, "INR", "ta", "₹ 1,234.56"},
+		{-3899, "USD", "en", "-$38.99"},
+		{-3899, "USD", "de-DE", "-38,99 $"},
+		{1500, "JPY", "en", "¥1,500"},
+		{1500, "XYZ", "en", "XYZ 15.00"},
+		{1500, "XYZ", "de", "15,00 XYZ"},
+		{123456, "", "de", "1.234,56"},
+		{5, "", "en", "0.05"},
+		{123456, "EUR", "fr", "€1,234.56"}, // unknown languages are written in English
+	}
+	for _, c := range cases {
+		got := strings.Replace(c.amount.FormatLocale(c.currency, c.language), "\u00a0", " ", -1)
+		if got != c.want {
+			t.Errorf("%d %s %s: got %q, want %q", c.amount, c.currency, c.language, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+//Money is an exact amount expressed in the minor units (cents, paise etc.) of its currency.
+//The currency itself is not part of the value. Use Parse and Format with the currency of the trip.
+type Money int64
+
+//DefaultExponent is the number of minor digits used for unknown currencies and for JSON.
+const DefaultExponent = 2
+
+var (
+	//ErrInvalidAmount is returned when the amount could not be parsed
+	ErrInvalidAmount = errors.New("Invalid amount")
+	//ErrTooPrecise is returned when the amount has more decimals than the currency allows
+	ErrTooPrecise = errors.New("Amount has more decimals than the currency allows")
+)
+
+// currencies whose minor unit is not a hundredth of the major unit
+var exponents = map[string]int{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+//Exponent returns the number of minor digits of the ISO 4217 currency code
+func Exponent(currency string) int {
+	if exponent, ok := exponents[strings.ToUpper(strings.TrimSpace(currency))]; ok {
+		return exponent
+	}
+	return DefaultExponent
+}
+
+//FromMinor creates money from the minor units
+func FromMinor(minor int64) Money {
+	return Money(minor)
+}
+
+//FromFloat converts a float amount with DefaultExponent minor digits, rounding half away from zero.
+//It is meant for the float based wrapper APIs only.
+func FromFloat(amount float64) Money {
+	return Money(math.Round(amount * math.Pow10(DefaultExponent)))
+}
+
+//Parse reads a decimal amount such as 12.5 or -3 in the currency
+func Parse(s string, currency string) (Money, error) {
+	return parse(strings.TrimSpace(s), Exponent(currency), false)
+}
+
+//Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return int64(m)
+}
+
+//Float64 returns the amount with DefaultExponent minor digits. It is meant for the float based wrapper APIs only.
+func (m Money) Float64() float64 {
+	return float64(m) / math.Pow10(DefaultExponent)
+}
+
+//Abs returns the absolute amount
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+//Split divides the amount in n parts which add up exactly to the amount.
+//The minor units which can't be divided equally are added to the first parts.
+func (m Money) Split(n int) []Money {
+	if n <= 0 {
+		return []Money{}
+	}
+	parts := make([]Money, n)
+	mean := m / Money(n)
+	remainder := m - mean*Money(n)
+	unit := Money(1)
+	if remainder < 0 {
+		unit = -1
+	}
+	for i := range parts {
+		parts[i] = mean
+		if remainder != 0 {
+			parts[i] += unit
+			remainder -= unit
+		}
+	}
+	return parts
+}
+
+//Allocate divides the amount in proportion to the weights. The parts add up exactly to the amount,
+//the minor units left after rounding down go to the parts with the largest fractions.
+func (m Money) Allocate(weights []int64) []Money {
+	parts := make([]Money, len(weights))
+	var total int64
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return parts
+	}
+
+	amount := big.NewInt(int64(m))
+	fractions := make([]int64, len(weights))
+	var allocated Money
+	for i, weight := range weights {
+		quotient, remainder := new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(weight)), big.NewInt(total), new(big.Int))
+		parts[i] = Money(quotient.Int64())
+		fractions[i] = remainder.Int64()
+		allocated += parts[i]
+	}
+
+	left := m - allocated
+	unit := Money(1)
+	if left < 0 {
+		unit = -1
+	}
+	for left != 0 {
+		largest := -1
+		for i, fraction := range fractions {
+			if weights[i] != 0 && (largest == -1 || abs64(fraction) > abs64(fractions[largest])) {
+				largest = i
+			}
+		}
+		parts[largest] += unit
+		fractions[largest] = 0
+		left -= unit
+	}
+	return parts
+}
+
+//Percent returns the basis points (hundredths of a percent) of the amount, rounding half away from zero
+func (m Money) Percent(basisPoints int64) Money {
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(basisPoints))
+	quotient, remainder := new(big.Int).QuoRem(product, big.NewInt(10000), new(big.Int))
+	if new(big.Int).Abs(remainder).Int64()*2 >= 10000 {
+		quotient.Add(quotient, big.NewInt(int64(product.Sign())))
+	}
+	return Money(quotient.Int64())
+}
+
+//Scale multiplies the amount by the factor, rounding half away from zero once eg. by 8.875/100 for a tax of 8.875%
+func (m Money) Scale(factor *big.Rat) Money {
+	scaled := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), factor)
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(scaled.Sign())))
+	}
+	return Money(quotient.Int64())
+}
+
+//Format writes the amount with the minor digits of the currency eg. 12.50 for USD and 1250 for JPY
+func (m Money) Format(currency string) string {
+	return format(m, Exponent(currency))
+}
+
+//String writes the amount with DefaultExponent minor digits
+func (m Money) String() string {
+	return format(m, DefaultExponent)
+}
+
+//MarshalJSON writes the amount as a decimal number with DefaultExponent minor digits,
+//the same way the float amounts were written before. It is the format the amounts are stored in and doesn't know
+//the currency, so amounts sent to clients are written with Format and read with Parse in their currency.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+//UnmarshalJSON reads a decimal number. Amounts written as floats are rounded to DefaultExponent minor digits.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*m = 0
+		return nil
+	}
+	parsed, err := parse(s, DefaultExponent, true)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func parse(s string, exponent int, round bool) (Money, error) {
+	rat, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, "/") {
+		return 0, ErrInvalidAmount
+	}
+	minor := new(big.Rat).Mul(rat, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)))
+	if !minor.IsInt() {
+		if !round {
+			return 0, ErrTooPrecise
+		}
+		// round half away from zero
+		half := big.NewRat(1, 2)
+		if minor.Sign() < 0 {
+			half.Neg(half)
+		}
+		minor.Add(minor, half)
+		return Money(new(big.Int).Quo(minor.Num(), minor.Denom()).Int64()), nil
+	}
+	if !minor.Num().IsInt64() {
+		return 0, ErrInvalidAmount
+	}
+	return Money(minor.Num().Int64()), nil
+}
+
+func format(m Money, exponent int) string {
+	sign := ""
+	minor := int64(m)
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	digits := strconv.FormatInt(minor, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParseAndFormat(t *testing.T) {
+	cases := []struct {
+		input    string
+		currency string
+		minor    int64
+		output   string
+	}{
+		{"12.5", "USD", 1250, "12.50"},
+		{"-3", "INR", -300, "-3.00"},
+		{"0.05", "EUR", 5, "0.05"},
+		{"1000", "JPY", 1000, "1000"},
+		{"1.234", "KWD", 1234, "1.234"},
+	}
+	for _, c := range cases {
+		m, err := Parse(c.input, c.currency)
+		if err != nil {
+			t.Fatalf("Parse(%q, %s) failed: %v", c.input, c.currency, err)
+		}
+		if m.Minor() != c.minor {
+			t.Errorf("Parse(%q, %s) = %d minor units, want %d", c.input, c.currency, m.Minor(), c.minor)
+		}
+		if got := m.Format(c.currency); got != c.output {
+			t.Errorf("Format(%s) = %q, want %q", c.currency, got, c.output)
+		}
+	}
+
+	if _, err := Parse("10.5", "JPY"); err != ErrTooPrecise {
+		t.Errorf("expected ErrTooPrecise for fractional yen, got %v", err)
+	}
+	if _, err := Parse("ten", "USD"); err != ErrInvalidAmount {
+		t.Errorf("expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+func TestSplitAddsUpToTheAmount(t *testing.T) {
+	parts := FromMinor(10000).Split(3)
+	if parts[0] != 3334 || parts[1] != 3333 || parts[2] != 3333 {
+		t.Errorf("unexpected split %v", parts)
+	}
+
+	for _, amount := range []Money{1, 100, 99999, -1001} {
+		for n := 1; n < 8; n++ {
+			var total Money
+			for _, part := range amount.Split(n) {
+				total += part
+			}
+			if total != amount {
+				t.Errorf("%d split %d ways adds up to %d", amount, n, total)
+			}
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	parts := FromMinor(10000).Allocate([]int64{1, 1, 1})
+	var total Money
+	for _, part := range parts {
+		total += part
+	}
+	if total != 10000 {
+		t.Errorf("allocation adds up to %d", total)
+	}
+
+	parts = FromMinor(500).Allocate([]int64{2, 3})
+	if parts[0] != 200 || parts[1] != 300 {
+		t.Errorf("unexpected allocation %v", parts)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	cases := []struct {
+		amount      Money
+		basisPoints int64
+		want        Money
+	}{
+		{10000, 1000, 1000},
+		{4550, 1250, 569}, // 568.75
+		{4550, 1800, 819},
+		{-4550, 1250, -569},
+		{1, 5000, 1}, // half rounds away from zero
+	}
+	for _, c := range cases {
+		if got := c.amount.Percent(c.basisPoints); got != c.want {
+			t.Errorf("%d percent of %d = %d, want %d", c.basisPoints, c.amount, got, c.want)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	cases := []struct {
+		amount Money
+		factor *big.Rat
+		want   Money
+	}{
+		{10000, big.NewRat(8875, 100000), 888}, // 887.5
+		{4550, big.NewRat(8875, 100000), 404},  // 403.8125
+		{-10000, big.NewRat(8875, 100000), -888},
+		{10000, new(big.Rat), 0},
+	}
+	for _, c := range cases {
+		if got := c.amount.Scale(c.factor); got != c.want {
+			t.Errorf("%d scaled by %s = %d, want %d", c.amount, c.factor.RatString(), got, c.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var values []Money
+	if err := json.Unmarshal([]byte(`[333.3333333333333, 12, "4.5", 1e2]`), &values); err != nil {
+		t.Fatal(err)
+	}
+	want := []Money{33333, 1200, 450, 10000}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("value %d = %d, want %d", i, values[i], want[i])
+		}
+	}
+
+	data, err := json.Marshal(FromMinor(-5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "-0.05" {
+		t.Errorf("marshalled to %s", data)
+	}
+}
@@ -0,0 +1,175 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	//ErrRateNotFound is returned when no rate between the currencies is effective on the date
+	ErrRateNotFound = errors.New("Exchange rate not found")
+	//ErrInvalidRate is returned when the rate is not a positive number or its currencies are missing
+	ErrInvalidRate = errors.New("Exchange rate should be a positive number between two currencies")
+)
+
+//Rate is the price of one unit of the base currency in the quote currency eg. 1 EUR = 1.08 USD.
+//The rate is effective from its date until the date of the next rate between the currencies.
+type Rate struct {
+	Base  string
+	Quote string
+	Date  time.Time
+	Value string // decimal kept as text so that the rate stays exact eg. 1.08
+}
+
+//Rates is a table of exchange rates looked up by the currencies and the date
+type Rates struct {
+	rates  map[string][]rate // by base and quote, oldest first
+	pivots []string          // currencies the missing rates are derived through
+}
+
+type rate struct {
+	date  time.Time
+	value *big.Rat
+}
+
+//ParseRate reads the rate as a positive decimal number
+func ParseRate(value string) (*big.Rat, error) {
+	value = strings.TrimSpace(value)
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok || rat.Sign() <= 0 || strings.ContainsAny(value, "/") {
+		return nil, ErrInvalidRate
+	}
+	return rat, nil
+}
+
+//NewRates creates the table of the rates. Rates missing between two currencies are derived
+//through the pivot currencies eg. INR to USD from the EUR to INR and EUR to USD rates of the ECB.
+func NewRates(rates []Rate, pivots ...string) (*Rates, error) {
+	table := &Rates{rates: make(map[string][]rate)}
+	for _, pivot := range pivots {
+		if pivot = normaliseCurrency(pivot); pivot != "" && !containsCurrency(table.pivots, pivot) {
+			table.pivots = append(table.pivots, pivot)
+		}
+	}
+	for _, r := range rates {
+		base, quote := normaliseCurrency(r.Base), normaliseCurrency(r.Quote)
+		value, err := ParseRate(r.Value)
+		if err != nil || base == "" || quote == "" || base == quote {
+			return nil, ErrInvalidRate
+		}
+		key := pairKey(base, quote)
+		table.rates[key] = append(table.rates[key], rate{date: r.Date, value: value})
+	}
+	for _, rates := range table.rates {
+		sort.SliceStable(rates, func(i, j int) bool { return rates[i].date.Before(rates[j].date) })
+	}
+	return table, nil
+}
+
+//Lookup returns the price of one unit of from in to effective on the date. The latest rate on or before
+//the date is used, so the days without rates like weekends fall back to the nearest earlier date.
+//Among the rate, the inverse of the rate between to and from and the rates derived through the pivots,
+//the most recent one is used.
+func (rates *Rates) Lookup(from, to string, date time.Time) (*big.Rat, error) {
+	from, to = normaliseCurrency(from), normaliseCurrency(to)
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+	if rates == nil {
+		return nil, ErrRateNotFound
+	}
+
+	best, ok := rates.pair(from, to, date)
+	for _, pivot := range rates.pivots {
+		if pivot == from || pivot == to {
+			continue
+		}
+		first, firstOk := rates.pair(from, pivot, date)
+		second, secondOk := rates.pair(pivot, to, date)
+		if !firstOk || !secondOk {
+			continue
+		}
+		// the cross rate is only as recent as its older leg
+		cross := rate{date: first.date, value: new(big.Rat).Mul(first.value, second.value)}
+		if second.date.Before(first.date) {
+			cross.date = second.date
+		}
+		if !ok || cross.date.After(best.date) {
+			best, ok = cross, true
+		}
+	}
+	if !ok {
+		return nil, ErrRateNotFound
+	}
+	return new(big.Rat).Set(best.value), nil
+}
+
+//Convert converts the amount from one currency to another at the rate effective on the date
+func (rates *Rates) Convert(amount Money, from, to string, date time.Time) (Money, error) {
+	value, err := rates.Lookup(from, to, date)
+	if err == ErrRateNotFound {
+		return 0, fmt.Errorf("%w from %s to %s on %s", err, normaliseCurrency(from), normaliseCurrency(to), date.Format("2006-01-02"))
+	}
+	if err != nil {
+		return 0, err
+	}
+	return amount.Exchange(value, from, to), nil
+}
+
+//Exchange converts the amount in the from currency to the to currency at the rate, rounding half away from zero.
+//The minor digits of both the currencies are taken care of.
+func (m Money) Exchange(value *big.Rat, from, to string) Money {
+	factor := new(big.Rat).Set(value)
+	shift := Exponent(to) - Exponent(from)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs64(int64(shift)))), nil))
+	if shift >= 0 {
+		factor.Mul(factor, scale)
+	} else {
+		factor.Quo(factor, scale)
+	}
+	return m.Scale(factor)
+}
+
+// pair returns the rate between the currencies or the inverse of the rate between quote and base, whichever is more recent
+func (rates *Rates) pair(base, quote string, date time.Time) (rate, bool) {
+	direct, directOk := rates.effective(base, quote, date)
+	inverse, inverseOk := rates.effective(quote, base, date)
+	switch {
+	case directOk && (!inverseOk || !direct.date.Before(inverse.date)):
+		return direct, true
+	case inverseOk:
+		return rate{date: inverse.date, value: new(big.Rat).Inv(inverse.value)}, true
+	}
+	return rate{}, false
+}
+
+// effective returns the latest rate between the currencies on or before the date
+func (rates *Rates) effective(base, quote string, date time.Time) (rate, bool) {
+	list := rates.rates[pairKey(base, quote)]
+	index := sort.Search(len(list), func(i int) bool { return list[i].date.After(date) })
+	if index == 0 {
+		return rate{}, false
+	}
+	return list[index-1], true
+}
+
+func containsCurrency(currencies []string, currency string) bool {
+	for _, existing := range currencies {
+		if existing == currency {
+			return true
+		}
+	}
+	return false
+}
+
+func pairKey(base, quote string) string {
+	return base + "/" + quote
+}
+
+func normaliseCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
@@ -0,0 +1,143 @@
+package money
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func day(value string) time.Time {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+func TestRatesLookup(t *testing.T) {
+	rates, err := NewRates([]Rate{
+		{Base: "EUR", Quote: "USD", Date: day("2026-01-01"), Value: "1.08"},
+		{Base: "eur", Quote: "usd", Date: day("2026-02-01"), Value: "1.10"},
+		{Base: "USD", Quote: "EUR", Date: day("2026-03-01"), Value: "0.8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		from, to string
+		date     string
+		want     string
+	}{
+		{"EUR", "USD", "2026-01-15", "27/25"},
+		{"EUR", "USD", "2026-02-01", "11/10"},
+		{"USD", "EUR", "2026-02-15", "10/11"},
+		{"EUR", "USD", "2026-03-15", "5/4"},
+		{"USD", "USD", "2025-01-01", "1"},
+	}
+	for _, c := range cases {
+		got, err := rates.Lookup(c.from, c.to, day(c.date))
+		if err != nil {
+			t.Errorf("Lookup(%s, %s, %s) failed: %v", c.from, c.to, c.date, err)
+			continue
+		}
+		if got.RatString() != c.want {
+			t.Errorf("Lookup(%s, %s, %s) = %s, want %s", c.from, c.to, c.date, got.RatString(), c.want)
+		}
+	}
+
+	if _, err := rates.Lookup("EUR", "USD", day("2025-12-31")); err != ErrRateNotFound {
+		t.Errorf("rate before the first date: got %v, want %v", err, ErrRateNotFound)
+	}
+	if _, err := rates.Lookup("EUR", "INR", day("2026-01-15")); err != ErrRateNotFound {
+		t.Errorf("unknown currency: got %v, want %v", err, ErrRateNotFound)
+	}
+}
+
+func TestNewRatesRejectsInvalidRates(t *testing.T) {
+	for _, rate := range []Rate{
+		{Base: "EUR", Quote: "USD", Value: "0"},
+		{Base: "EUR", Quote: "USD", Value: "-1.08"},
+		{Base: "EUR", Quote: "USD", Value: "1/2"},
+		{Base: "EUR", Quote: "EUR", Value: "1"},
+		{Base: "", Quote: "USD", Value: "1.08"},
+	} {
+		if _, err := NewRates([]Rate{rate}); err != ErrInvalidRate {
+			t.Errorf("NewRates(%+v) = %v, want %v", rate, err, ErrInvalidRate)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	cases := []struct {
+		amount   Money
+		rate     string
+		from, to string
+		want     Money
+	}{
+		{1000, "1.08", "EUR", "USD", 1080},
+		{1, "1.5", "EUR", "USD", 2},          // 1.5 cents rounds up
+		{-1, "1.5", "EUR", "USD", -2},        // away from zero
+		{1000, "160.25", "USD", "JPY", 1603}, // 10.00 USD is 1602.5 JPY
+		{1603, "0.0062", "JPY", "USD", 994},
+		{1000, "0.3", "USD", "KWD", 3000},
+	}
+	for _, c := range cases {
+		rate, err := ParseRate(c.rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.amount.Exchange(rate, c.from, c.to); got != c.want {
+			t.Errorf("%d %s at %s in %s = %d, want %d", c.amount, c.from, c.rate, c.to, got, c.want)
+		}
+	}
+}
+
+func TestRatesConvert(t *testing.T) {
+	rates, err := NewRates([]Rate{{Base: "USD", Quote: "JPY", Date: day("2026-01-01"), Value: "150"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := rates.Convert(3000, "JPY", "USD", day("2026-01-02")); err != nil || got != 2000 {
+		t.Errorf("Convert = %d, %v, want 2000", got, err)
+	}
+	_, err = rates.Convert(3000, "JPY", "EUR", day("2026-01-02"))
+	if !errors.Is(err, ErrRateNotFound) || err.Error() != "Exchange rate not found from JPY to EUR on 2026-01-02" {
+		t.Errorf("got %v, want %v", err, ErrRateNotFound)
+	}
+}
+
+func TestRatesLookupThroughPivot(t *testing.T) {
+	rates, err := NewRates([]Rate{
+		{Base: "EUR", Quote: "USD", Date: day("2026-01-29"), Value: "1.25"},
+		{Base: "EUR", Quote: "INR", Date: day("2026-01-29"), Value: "100"},
+		{Base: "EUR", Quote: "INR", Date: day("2026-01-30"), Value: "90"},
+		{Base: "USD", Quote: "INR", Date: day("2026-01-01"), Value: "82"},
+		{Base: "GBP", Quote: "USD", Date: day("2026-01-29"), Value: "1.5"},
+	}, "EUR", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		from, to string
+		date     string
+		want     string
+	}{
+		{"USD", "INR", "2026-01-29", "80"},   // through EUR, more recent than the USD INR rate
+		{"USD", "INR", "2026-01-15", "82"},   // only the USD INR rate is effective
+		{"INR", "USD", "2026-02-01", "1/72"}, // through EUR on the weekend, the USD leg falls back further
+		{"GBP", "EUR", "2026-01-31", "6/5"},  // through USD
+		{"GBP", "INR", "2026-01-31", "123"},  // through USD with its own INR rate, pivots are not chained
+	}
+	for _, c := range cases {
+		got, err := rates.Lookup(c.from, c.to, day(c.date))
+		if err != nil {
+			t.Errorf("Lookup(%s, %s, %s) failed: %v", c.from, c.to, c.date, err)
+			continue
+		}
+		if got.RatString() != c.want {
+			t.Errorf("Lookup(%s, %s, %s) = %s, want %s", c.from, c.to, c.date, got.RatString(), c.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"os"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/mailer"
+)
+
+//Mail emails the notifications through the sender
+type Mail struct {
+	Sender mailer.Sender
+	From   string
+}
+
+//Message is the email of the notification, with its HTML version
+func (channel *Mail) Message(notification *Notification) (*mailer.Message, error) {
+	if notification.To.Email == "" {
+		return nil, ErrNoEmail
+	}
+	message := &mailer.Message{
+		From:    channel.From,
+		To:      []string{address(notification.To)},
+		Subject: notification.Subject,
+		Body:    notification.Text,
+		HTML:    notification.HTML,
+		Date:    notification.Date,
+	}
+	for _, cc := range notification.Cc {
+		if cc.Email != "" {
+			message.Cc = append(message.Cc, address(cc))
+		}
+	}
+	return message, nil
+}
+
+//Send emails the notification to the recipient and those copied in
+func (channel *Mail) Send(notification *Notification) error {
+	message, err := channel.Message(notification)
+	if err != nil {
+		return err
+	}
+	return channel.Sender.Send(message)
+}
+
+// address formats the recipient for the mail headers, quoting the name and encoding it when not ASCII
+func address(recipient Recipient) string {
+	return (&mail.Address{Name: recipient.Name, Address: recipient.Email}).String()
+}
+
+//Webhook posts the notifications as JSON to the URL
+type Webhook struct {
+	URL    string
+	Client *http.Client // http.DefaultClient if not given
+}
+
+// webhookPayload is the JSON posted to the webhook
+type webhookPayload struct {
+	Event   Kind        `json:"event"`
+	To      Recipient   `json:"to"`
+	Cc      []Recipient `json:"cc,omitempty"`
+	Subject string      `json:"subject"`
+	Text    string      `json:"text"`
+	HTML    string      `json:"html"`
+	Data    interface{} `json:"data"`
+	Date    time.Time   `json:"date"`
+}
+
+//Send posts the notification. Any response other than 2xx is an error.
+func (channel *Webhook) Send(notification *Notification) error {
+	date := notification.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	body, err := json.Marshal(webhookPayload{
+		Event:   notification.Kind,
+		To:      notification.To,
+		Cc:      notification.Cc,
+		Subject: notification.Subject,
+		Text:    notification.Text,
+		HTML:    notification.HTML,
+		Data:    notification.Data,
+		Date:    date,
+	})
+	if err != nil {
+		return err
+	}
+
+	client := channel.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Post(channel.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("Webhook responded %s", response.Status)
+	}
+	return nil
+}
+
+//Writer writes the text of the notifications, eg. to the stdout
+type Writer struct {
+	Out io.Writer
+}
+
+//Send writes the recipient, the subject and the text of the notification
+func (channel *Writer) Send(notification *Notification) error {
+	to := notification.To.Name
+	if notification.To.Email != "" {
+		to = fmt.Sprintf("%s <%s>", notification.To.Name, notification.To.Email) // read by people, not parsed
+	}
+	_, err := fmt.Fprintf(channel.Out, "To: %s\nSubject: %s\n\n%s\n", to, notification.Subject, notification.Text)
+	return err
+}
+
+//File appends the text of the notifications to the file
+type File struct {
+	Path string
+}
+
+//Send appends the notification to the file, creating the file if needed
+func (channel *File) Send(notification *Notification) error {
+	file, err := os.OpenFile(channel.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := (&Writer{Out: file}).Send(notification); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+//Fake keeps the notifications sent through it instead of delivering them, for testing offline
+type Fake struct {
+	Sent []*Notification
+	Err  error // returned by Send when set, the notification is not kept
+}
+
+//Send keeps the notification
+func (channel *Fake) Send(notification *Notification) error {
+	if channel.Err != nil {
+		return channel.Err
+	}
+	channel.Sent = append(channel.Sent, notification)
+	return nil
+}
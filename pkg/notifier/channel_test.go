@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sankarvj/expensesplitter/pkg/mailer"
+)
+
+// fakeSender keeps the messages instead of sending them
+type fakeSender struct {
+	sent []*mailer.Message
+}
+
+func (sender *fakeSender) Send(message *mailer.Message) error {
+	sender.sent = append(sender.sent, message)
+	return nil
+}
+
+func testNotification(t *testing.T) *Notification {
+	notification, err := DefaultTemplates().Render(Settled, Recipient{Name: "walt", Email: "walt@example.com"}, testSettlement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return notification
+}
+
+func TestMailSend(t *testing.T) {
+	sender := &fakeSender{}
+	channel := &Mail{Sender: sender, From: "Trips <trips@example.com>"}
+	notification := testNotification(t)
+	notification.Cc = []Recipient{{Name: "gus", Email: "gus@example.com"}, {Name: "jesse"}}
+	if err := channel.Send(notification); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("got %d messages, want 1", len(sender.sent))
+	}
+	message := sender.sent[0]
+	if strings.Join(message.To, ", ") != `"walt" <walt@example.com>` || strings.Join(message.Cc, ", ") != `"gus" <gus@example.com>` || message.From != channel.From {
+		t.Errorf("got %+v", message)
+	}
+	if message.Subject != notification.Subject || message.Body != notification.Text || message.HTML != notification.HTML {
+		t.Errorf("got %+v", message)
+	}
+
+	// the names with specials or accents should survive the parsing done when sending
+	notification.To = Recipient{Name: "White, José", Email: "walt@example.com"}
+	message, err := channel.Message(notification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := mail.ParseAddress(message.To[0])
+	if err != nil || parsed.Name != "White, José" || parsed.Address != "walt@example.com" {
+		t.Errorf("got %q parsed as %v, %v", message.To[0], parsed, err)
+	}
+
+	notification.To.Email = ""
+	if err := channel.Send(notification); err != ErrNoEmail {
+		t.Errorf("expected ErrNoEmail, got %v", err)
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	var got map[string]interface{}
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	channel := &Webhook{URL: server.URL}
+	if err := channel.Send(testNotification(t)); err != nil {
+		t.Fatal(err)
+	}
+	if got["event"] != "settled" || got["subject"] != "jesse paid walt 40.00 EUR for goa" {
+		t.Errorf("got %v", got)
+	}
+	if to := got["to"].(map[string]interface{}); to["name"] != "walt" || to["email"] != "walt@example.com" {
+		t.Errorf("got to %v", to)
+	}
+	if data := got["data"].(map[string]interface{}); data["Amount"] != "40.00 EUR" {
+		t.Errorf("got data %v", data)
+	}
+
+	status = http.StatusBadGateway
+	if err := channel.Send(testNotification(t)); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("expected the 502 to fail, got %v", err)
+	}
+}
+
+func TestWriterSend(t *testing.T) {
+	var out bytes.Buffer
+	if err := (&Writer{Out: &out}).Send(testNotification(t)); err != nil {
+		t.Fatal(err)
+	}
+	want := "To: walt <walt@example.com>\nSubject: jesse paid walt 40.00 EUR for goa\n\nHi walt,\n"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("got %q, want prefix %q", out.String(), want)
+	}
+}
+
+func TestFileSend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notifications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	channel := &File{Path: filepath.Join(dir, "notifications.txt")}
+	for i := 0; i < 2; i++ {
+		if err := channel.Send(testNotification(t)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := ioutil.ReadFile(channel.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(string(data), "Subject: jesse paid walt"); count != 2 {
+		t.Errorf("got %d notifications in the file, want 2", count)
+	}
+}
@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"errors"
+	"time"
+)
+
+//Kind is the event a notification is sent for
+type Kind string
+
+//Kinds of the notifications. Each has its own templates named after it.
+const (
+	NewExpense    Kind = "new_expense"
+	YouOwe        Kind = "you_owe"
+	Settled       Kind = "settled"
+	WeeklySummary Kind = "weekly_summary"
+)
+
+//Kinds lists every kind of notification
+var Kinds = []Kind{NewExpense, YouOwe, Settled, WeeklySummary}
+
+var (
+	//ErrUnknownKind is returned for a notification kind without templates
+	ErrUnknownKind = errors.New("Unknown notification kind")
+	//ErrNoEmail is returned when a notification is emailed to a recipient without email
+	ErrNoEmail = errors.New("Recipient has no email")
+)
+
+//Recipient is the member a notification is sent to
+type Recipient struct {
+	Name     string `json:"name"`
+	Email    string `json:"email,omitempty"`
+	Language string `json:"language,omitempty"` // language the recipient reads eg. es, English if empty
+}
+
+//Notification is a rendered message ready to be delivered through the channels
+type Notification struct {
+	Kind    Kind
+	To      Recipient
+	Cc      []Recipient // recipients copied in (Optional)
+	Subject string
+	Text    string
+	HTML    string
+	Data    interface{} // data the notification is rendered from
+	Date    time.Time   // time of sending if not given
+}
+
+//Channel delivers the notifications
+type Channel interface {
+	Send(notification *Notification) error
+}
+
+//Notifier renders the notifications with the templates and delivers them through every channel
+type Notifier struct {
+	Templates *Templates
+	Channels  []Channel
+}
+
+//New returns the notifier delivering through the channels with the default templates
+func New(channels ...Channel) *Notifier {
+	return &Notifier{Templates: DefaultTemplates(), Channels: channels}
+}
+
+//Notify renders the notification of the kind for the recipient and delivers it through every channel
+func (notifier *Notifier) Notify(kind Kind, to Recipient, data interface{}) error {
+	notification, err := notifier.Templates.Render(kind, to, data)
+	if err != nil {
+		return err
+	}
+	return notifier.Send(notification)
+}
+
+//Send delivers the notification through every channel.
+//A failing channel doesn't stop the others, the first error is returned.
+func (notifier *Notifier) Send(notification *Notification) error {
+	var first error
+	for _, channel := range notifier.Channels {
+		if err := channel.Send(notification); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testSettlement() Settlement {
+	return Settlement{
+		Trip:    "goa",
+		Member:  "walt",
+		Payment: Payment{Date: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), From: "jesse", To: "walt", Amount: "40.00 EUR"},
+	}
+}
+
+func TestNotifierNotify(t *testing.T) {
+	fake := &Fake{}
+	notifier := New(fake)
+	to := Recipient{Name: "walt", Email: "walt@example.com"}
+	if err := notifier.Notify(Settled, to, testSettlement()); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Sent) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(fake.Sent))
+	}
+	got := fake.Sent[0]
+	if got.Kind != Settled || got.To != to || got.Subject != "jesse paid walt 40.00 EUR for goa" {
+		t.Errorf("got %+v", got)
+	}
+
+	if err := notifier.Notify(Kind("birthday"), to, nil); err != ErrUnknownKind {
+		t.Errorf("expected ErrUnknownKind, got %v", err)
+	}
+}
+
+func TestNotifierSendTriesEveryChannel(t *testing.T) {
+	failed := errors.New("down")
+	cases := []struct {
+		channels []*Fake
+		want     error
+		sent     []int
+	}{
+		{[]*Fake{{}, {}}, nil, []int{1, 1}},
+		{[]*Fake{{Err: failed}, {}}, failed, []int{0, 1}},
+		{[]*Fake{{}, {Err: failed}, {Err: errors.New("later")}}, failed, []int{1, 0, 0}},
+	}
+	for _, c := range cases {
+		notifier := New()
+		for _, channel := range c.channels {
+			notifier.Channels = append(notifier.Channels, channel)
+		}
+		if err := notifier.Send(&Notification{Kind: Settled}); err != c.want {
+			t.Errorf("got %v, want %v", err, c.want)
+		}
+		for i, channel := range c.channels {
+			if len(channel.Sent) != c.sent[i] {
+				t.Errorf("channel %d got %d notifications, want %d", i, len(channel.Sent), c.sent[i])
+			}
+		}
+	}
+}
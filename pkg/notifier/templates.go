@@ -0,0 +1,337 @@
+package notifier
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+//Item is a transaction listed in the notifications. Amounts are formatted in their currency.
+type Item struct {
+	Date   time.Time
+	Name   string
+	Amount string
+	Share  string // share of the recipient, empty if the recipient has none
+	Paid   string // amount paid by the recipient
+}
+
+//Debt is an amount the recipient has to pay to another member
+type Debt struct {
+	To     string
+	Amount string
+}
+
+//Payment is a settlement made between two members
+type Payment struct {
+	Date   time.Time
+	From   string
+	To     string
+	Amount string
+	Note   string
+}
+
+//Balance is where a member stands in the trip
+type Balance struct {
+	Member string
+	Amount string
+	Owes   bool // member owes the amount, otherwise gets it back
+}
+
+//Expense is the data of the NewExpense notification
+type Expense struct {
+	Trip   string
+	Member string // recipient
+	Item
+	PaidBy []string
+}
+
+//Owe is the data of the YouOwe notification
+type Owe struct {
+	Trip      string
+	Member    string // recipient
+	Debts     []Debt
+	Total     string
+	Items     []Item    // transactions the recipient took part in
+	Payments  []Payment // settlements the recipient made or got
+	Escalated bool
+	Reminder  int      // number of the reminder, from 1
+	Copied    []string // members owed who are copied in when escalated
+}
+
+//Settlement is the data of the Settled notification
+type Settlement struct {
+	Trip   string
+	Member string // recipient
+	Payment
+}
+
+//Summary is the data of the WeeklySummary notification
+type Summary struct {
+	Trip     string
+	Member   string // recipient
+	From     time.Time
+	To       time.Time
+	Spent    string // total of the transactions of the period
+	Items    []Item // transactions of the period
+	Balances []Balance
+	Debts    []Debt // what the recipient has to pay
+}
+
+var funcs = map[string]interface{}{
+	"date": func(t time.Time) string { return t.Format("2006-01-02") },
+	"join": func(values []string) string { return strings.Join(values, ", ") },
+}
+
+// defaults are the subject, text and HTML templates of each kind
+var defaults = map[Kind][3]string{
+	NewExpense: {
+		`{{join .PaidBy}} added {{.Name}} to {{.Trip}}`,
+		`Hi {{.Member}},
+
+{{join .PaidBy}} paid {{.Amount}} for {{.Name}} on {{date .Date}} in {{.Trip}}.
+{{if .Share}}Your share is {{.Share}}{{if .Paid}} and you paid {{.Paid}}{{end}}.
+{{end}}`,
+		`<p>Hi {{.Member}},</p>
+<p>{{join .PaidBy}} paid <b>{{.Amount}}</b> for {{.Name}} on {{date .Date}} in {{.Trip}}.</p>
+{{if .Share}}<p>Your share is <b>{{.Share}}</b>{{if .Paid}} and you paid {{.Paid}}{{end}}.</p>
+{{end}}`,
+	},
+	YouOwe: {
+		`{{if .Escalated}}Overdue{{else}}Reminder{{end}}: you owe {{.Total}} for {{.Trip}}`,
+		`Hi {{.Member}},
+
+This is a reminder of what you owe for {{.Trip}}.
+
+{{range .Debts}}  Pay {{.To}} {{.Amount}}
+{{end}}
+In total you owe {{.Total}}.
+{{if .Items}}
+Your transactions:
+{{range .Items}}  {{date .Date}}  {{.Name}}  {{.Amount}}, your share {{.Share}}, you paid {{.Paid}}
+{{end}}{{end}}{{if .Payments}}
+Your settlements:
+{{range .Payments}}  {{date .Date}}  {{.From}} paid {{.To}} {{.Amount}}
+{{end}}{{end}}{{if .Escalated}}
+This is reminder {{.Reminder}}{{if .Copied}}, {{join .Copied}} copied in{{end}}.
+{{end}}`,
+		`<p>Hi {{.Member}},</p>
+<p>This is a reminder of what you owe for {{.Trip}}.</p>
+<ul>
+{{range .Debts}}<li>Pay {{.To}} <b>{{.Amount}}</b></li>
+{{end}}</ul>
+<p>In total you owe <b>{{.Total}}</b>.</p>
+{{if .Items}}<table>
+<tr><th>Date</th><th>Name</th><th>Amount</th><th>Your share</th><th>You paid</th></tr>
+{{range .Items}}<tr><td>{{date .Date}}</td><td>{{.Name}}</td><td>{{.Amount}}</td><td>{{.Share}}</td><td>{{.Paid}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Payments}}<ul>
+{{range .Payments}}<li>{{date .Date}} {{.From}} paid {{.To}} {{.Amount}}</li>
+{{end}}</ul>
+{{end}}{{if .Escalated}}<p>This is reminder {{.Reminder}}{{if .Copied}}, {{join .Copied}} copied in{{end}}.</p>
+{{end}}`,
+	},
+	Settled: {
+		`{{.From}} paid {{.To}} {{.Amount}} for {{.Trip}}`,
+		`Hi {{.Member}},
+
+{{.From}} paid {{.To}} {{.Amount}} on {{date .Date}} to settle up {{.Trip}}.
+{{if .Note}}Note: {{.Note}}
+{{end}}`,
+		`<p>Hi {{.Member}},</p>
+<p>{{.From}} paid {{.To}} <b>{{.Amount}}</b> on {{date .Date}} to settle up {{.Trip}}.</p>
+{{if .Note}}<p>Note: {{.Note}}</p>
+{{end}}`,
+	},
+	WeeklySummary: {
+		`{{.Trip}} from {{date .From}} to {{date .To}}`,
+		`Hi {{.Member}},
+
+{{if .Items}}{{len .Items}} transactions of {{.Spent}} were added to {{.Trip}} from {{date .From}} to {{date .To}}.
+
+{{range .Items}}  {{date .Date}}  {{.Name}}  {{.Amount}}{{if .Share}}, your share {{.Share}}{{end}}
+{{end}}{{else}}Nothing was added to {{.Trip}} from {{date .From}} to {{date .To}}.
+{{end}}{{if .Balances}}
+Balances:
+{{range .Balances}}  {{.Member}} {{if .Owes}}owes{{else}}gets back{{end}} {{.Amount}}
+{{end}}{{end}}
+{{if .Debts}}You have to pay{{range .Debts}} {{.To}} {{.Amount}}{{end}}.{{else}}You don't owe anything.{{end}}
+`,
+		`<p>Hi {{.Member}},</p>
+{{if .Items}}<p>{{len .Items}} transactions of <b>{{.Spent}}</b> were added to {{.Trip}} from {{date .From}} to {{date .To}}.</p>
+<table>
+{{range .Items}}<tr><td>{{date .Date}}</td><td>{{.Name}}</td><td>{{.Amount}}</td><td>{{.Share}}</td></tr>
+{{end}}</table>
+{{else}}<p>Nothing was added to {{.Trip}} from {{date .From}} to {{date .To}}.</p>
+{{end}}{{if .Balances}}<ul>
+{{range .Balances}}<li>{{.Member}} {{if .Owes}}owes{{else}}gets back{{end}} {{.Amount}}</li>
+{{end}}</ul>
+{{end}}<p>{{if .Debts}}You have to pay{{range .Debts}} {{.To}} <b>{{.Amount}}</b>{{end}}.{{else}}You don't owe anything.{{end}}</p>
+`,
+	},
+}
+
+// parts of the notification rendered by the templates
+var parts = []string{"subject", "txt", "html"}
+
+//Templates renders the subject and the text and HTML bodies of each kind of notification
+type Templates struct {
+	subjects  map[Kind]*texttemplate.Template
+	texts     map[Kind]*texttemplate.Template
+	htmls     map[Kind]*htmltemplate.Template
+	languages map[string]*Templates // templates for the recipients reading another language
+}
+
+//DefaultTemplates returns the templates built in
+func DefaultTemplates() *Templates {
+	templates := &Templates{
+		subjects:  make(map[Kind]*texttemplate.Template),
+		texts:     make(map[Kind]*texttemplate.Template),
+		htmls:     make(map[Kind]*htmltemplate.Template),
+		languages: make(map[string]*Templates),
+	}
+	for kind, sources := range defaults {
+		// the defaults are known to parse
+		templates.subjects[kind] = texttemplate.Must(parseText(string(kind)+".subject", sources[0]))
+		templates.texts[kind] = texttemplate.Must(parseText(string(kind)+".txt", sources[1]))
+		templates.htmls[kind] = htmltemplate.Must(parseHTML(string(kind)+".html", sources[2]))
+	}
+	return templates
+}
+
+//LoadTemplates returns the default templates overridden by the files of the directory.
+//Files are named after the kind and the part they render eg. you_owe.subject.tmpl, you_owe.txt.tmpl and you_owe.html.tmpl.
+//The ones with a language in between eg. you_owe.es.txt.tmpl are rendered for the recipients reading that language,
+//falling back to the other templates for the parts not translated.
+func LoadTemplates(dir string) (*Templates, error) {
+	templates := DefaultTemplates()
+	for _, kind := range Kinds {
+		for _, part := range parts {
+			name := string(kind) + "." + part
+			data, err := ioutil.ReadFile(filepath.Join(dir, name+".tmpl"))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if err := templates.parse(kind, part, name, string(data)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.*.*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".tmpl")
+		fields := strings.Split(name, ".")
+		if len(fields) != 3 || !knownPart(fields[2]) {
+			continue
+		}
+		kind, language, part := Kind(fields[0]), money.BaseLanguage(fields[1]), fields[2]
+		if _, ok := templates.subjects[kind]; !ok || language == "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		translated, ok := templates.languages[language]
+		if !ok {
+			translated = templates.clone()
+			templates.languages[language] = translated
+		}
+		if err := translated.parse(kind, part, name, string(data)); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// parse sets the template of the part of the kind from the source
+func (templates *Templates) parse(kind Kind, part string, name string, source string) error {
+	var err error
+	switch part {
+	case "subject":
+		templates.subjects[kind], err = parseText(name, source)
+	case "txt":
+		templates.texts[kind], err = parseText(name, source)
+	case "html":
+		templates.htmls[kind], err = parseHTML(name, source)
+	}
+	return err
+}
+
+// clone copies the templates of every kind without the languages, so a language overrides only its own parts
+func (templates *Templates) clone() *Templates {
+	cloned := &Templates{
+		subjects: make(map[Kind]*texttemplate.Template),
+		texts:    make(map[Kind]*texttemplate.Template),
+		htmls:    make(map[Kind]*htmltemplate.Template),
+	}
+	for kind := range templates.subjects {
+		cloned.subjects[kind] = templates.subjects[kind]
+		cloned.texts[kind] = templates.texts[kind]
+		cloned.htmls[kind] = templates.htmls[kind]
+	}
+	return cloned
+}
+
+func knownPart(part string) bool {
+	for _, known := range parts {
+		if part == known {
+			return true
+		}
+	}
+	return false
+}
+
+//Render renders the notification of the kind for the recipient from the data.
+//The templates of the language of the recipient are used when loaded.
+func (templates *Templates) Render(kind Kind, to Recipient, data interface{}) (*Notification, error) {
+	if translated, ok := templates.languages[money.BaseLanguage(to.Language)]; ok {
+		templates = translated
+	}
+	subject, ok := templates.subjects[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+	notification := &Notification{Kind: kind, To: to, Data: data}
+
+	var buffer bytes.Buffer
+	if err := subject.Execute(&buffer, data); err != nil {
+		return nil, err
+	}
+	// subjects are a single line however the template is written
+	notification.Subject = strings.Join(strings.Fields(buffer.String()), " ")
+
+	buffer.Reset()
+	if err := templates.texts[kind].Execute(&buffer, data); err != nil {
+		return nil, err
+	}
+	notification.Text = buffer.String()
+
+	buffer.Reset()
+	if err := templates.htmls[kind].Execute(&buffer, data); err != nil {
+		return nil, err
+	}
+	notification.HTML = buffer.String()
+	return notification, nil
+}
+
+func parseText(name, source string) (*texttemplate.Template, error) {
+	return texttemplate.New(name).Funcs(funcs).Option("missingkey=error").Parse(source)
+}
+
+func parseHTML(name, source string) (*htmltemplate.Template, error) {
+	return htmltemplate.New(name).Funcs(funcs).Option("missingkey=error").Parse(source)
+}
@@ -0,0 +1,191 @@
+package notifier
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testDate = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func TestTemplatesRender(t *testing.T) {
+	cases := []struct {
+		kind    Kind
+		data    interface{}
+		subject string
+		text    []string
+		html    []string
+	}{
+		{
+			NewExpense,
+			Expense{Trip: "goa", Member: "jesse", Item: Item{Date: testDate, Name: "hotel", Amount: "300.00", Share: "150.00"}, PaidBy: []string{"walt"}},
+			"walt added hotel to goa",
+			[]string{"Hi jesse,", "walt paid 300.00 for hotel on 2026-03-01 in goa.", "Your share is 150.00."},
+			[]string{"<b>300.00</b>", "Your share is <b>150.00</b>."},
+		},
+		{
+			YouOwe,
+			Owe{Trip: "goa", Member: "jesse", Debts: []Debt{{To: "walt", Amount: "40.00"}, {To: "gus", Amount: "10.00"}}, Total: "50.00",
+				Items: []Item{{Date: testDate, Name: "hotel", Amount: "300.00", Share: "150.00", Paid: "0.00"}}},
+			"Reminder: you owe 50.00 for goa",
+			[]string{"  Pay walt 40.00\n  Pay gus 10.00\n", "In total you owe 50.00.", "2026-03-01  hotel  300.00, your share 150.00, you paid 0.00"},
+			[]string{"<li>Pay walt <b>40.00</b></li>", "<td>hotel</td>"},
+		},
+		{
+			YouOwe,
+			Owe{Trip: "goa", Member: "jesse", Debts: []Debt{{To: "walt", Amount: "40.00"}}, Total: "40.00", Escalated: true, Reminder: 4, Copied: []string{"walt"}},
+			"Overdue: you owe 40.00 for goa",
+			[]string{"This is reminder 4, walt copied in."},
+			[]string{"This is reminder 4, walt copied in."},
+		},
+		{
+			Settled,
+			Settlement{Trip: "goa", Member: "walt", Payment: Payment{Date: testDate, From: "jesse", To: "walt", Amount: "40.00", Note: "cash"}},
+			"jesse paid walt 40.00 for goa",
+			[]string{"jesse paid walt 40.00 on 2026-03-01 to settle up goa.", "Note: cash"},
+			[]string{"<p>Note: cash</p>"},
+		},
+		{
+			WeeklySummary,
+			Summary{Trip: "goa", Member: "jesse", From: testDate, To: testDate.AddDate(0, 0, 7), Spent: "390.00",
+				Items:    []Item{{Date: testDate, Name: "hotel", Amount: "300.00", Share: "150.00"}, {Date: testDate, Name: "dinner", Amount: "90.00"}},
+				Balances: []Balance{{Member: "walt", Amount: "150.00"}, {Member: "jesse", Amount: "150.00", Owes: true}},
+				Debts:    []Debt{{To: "walt", Amount: "150.00"}}},
+			"goa from 2026-03-01 to 2026-03-08",
+			[]string{"2 transactions of 390.00 were added", "hotel  300.00, your share 150.00\n", "dinner  90.00\n", "walt gets back 150.00", "jesse owes 150.00", "You have to pay walt 150.00."},
+			[]string{"<b>390.00</b>", "<li>jesse owes 150.00</li>"},
+		},
+		{
+			WeeklySummary,
+			Summary{Trip: "goa", Member: "jesse", From: testDate, To: testDate.AddDate(0, 0, 7)},
+			"goa from 2026-03-01 to 2026-03-08",
+			[]string{"Nothing was added to goa", "You don't owe anything."},
+			[]string{"You don't owe anything."},
+		},
+	}
+	templates := DefaultTemplates()
+	for _, c := range cases {
+		notification, err := templates.Render(c.kind, Recipient{Name: "jesse"}, c.data)
+		if err != nil {
+			t.Fatalf("%s: %v", c.kind, err)
+		}
+		if notification.Subject != c.subject {
+			t.Errorf("%s: got subject %q, want %q", c.kind, notification.Subject, c.subject)
+		}
+		for _, want := range c.text {
+			if !strings.Contains(notification.Text, want) {
+				t.Errorf("%s: text doesn't have %q:\n%s", c.kind, want, notification.Text)
+			}
+		}
+		for _, want := range c.html {
+			if !strings.Contains(notification.HTML, want) {
+				t.Errorf("%s: html doesn't have %q:\n%s", c.kind, want, notification.HTML)
+			}
+		}
+	}
+}
+
+func TestTemplatesEscapeHTML(t *testing.T) {
+	settlement := testSettlement()
+	settlement.Note = "<script>alert(1)</script>"
+	notification, err := DefaultTemplates().Render(Settled, Recipient{Name: "walt"}, settlement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(notification.HTML, "<script>") || !strings.Contains(notification.Text, "<script>") {
+		t.Errorf("expected the note escaped in the html only:\n%s\n%s", notification.HTML, notification.Text)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, source string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("settled.subject.tmpl", "{{.From}} settled up\n")
+	write("settled.txt.tmpl", "{{.Amount}} from {{.From}}")
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notification, err := templates.Render(Settled, Recipient{Name: "walt"}, testSettlement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notification.Subject != "jesse settled up" || notification.Text != "40.00 EUR from jesse" {
+		t.Errorf("got %q %q", notification.Subject, notification.Text)
+	}
+	// the templates not overridden are the defaults
+	if !strings.Contains(notification.HTML, "to settle up goa") {
+		t.Errorf("expected the default html, got %s", notification.HTML)
+	}
+
+	write("you_owe.html.tmpl", "{{.Total")
+	if _, err := LoadTemplates(dir); err == nil {
+		t.Errorf("expected the broken template to fail")
+	}
+
+	write("you_owe.html.tmpl", "{{.Balance}}")
+	templates, err = LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := templates.Render(YouOwe, Recipient{Name: "walt"}, Owe{}); err == nil {
+		t.Errorf("expected the unknown field to fail")
+	}
+}
+
+func TestLoadTemplatesLanguage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, source string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("settled.txt.tmpl", "{{.Amount}} from {{.From}}")
+	write("settled.es.subject.tmpl", "{{.From}} pagó a {{.To}}")
+	write("settled.es.txt.tmpl", "{{.Amount}} de {{.From}}")
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notification, err := templates.Render(Settled, Recipient{Name: "walt", Language: "es-MX"}, testSettlement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notification.Subject != "jesse pagó a walt" || notification.Text != "40.00 EUR de jesse" {
+		t.Errorf("got %q %q", notification.Subject, notification.Text)
+	}
+	// the parts not translated fall back to the default
+	if !strings.Contains(notification.HTML, "to settle up goa") {
+		t.Errorf("expected the default html, got %s", notification.HTML)
+	}
+
+	notification, err = templates.Render(Settled, Recipient{Name: "walt", Language: "de"}, testSettlement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notification.Text != "40.00 EUR from jesse" {
+		t.Errorf("expected the templates without a language for de, got %q", notification.Text)
+	}
+
+	write("settled.es.html.tmpl", "{{.Amount")
+	if _, err := LoadTemplates(dir); err == nil {
+		t.Errorf("expected the broken translated template to fail")
+	}
+}
@@ -0,0 +1,116 @@
+package reminder
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//ErrInvalidPolicy is returned when the days of the policy are negative or the quiet hours are not HH:MM
+var ErrInvalidPolicy = errors.New("Reminders need a positive cadence and quiet hours given as HH:MM")
+
+//DefaultPolicy reminds 3 days after a transaction, then weekly and copies the creditors in after three reminders
+var DefaultPolicy = Policy{First: 3, Every: 7, Escalate: 3}
+
+//Policy decides when a member who owes money is reminded
+type Policy struct {
+	First     int    // days after the latest transaction of the member before reminding
+	Every     int    // days between the reminders that follow
+	Escalate  int    // reminders after which the creditors are copied in, never when zero
+	QuietFrom string `json:",omitempty"` // HH:MM from which no reminder is sent
+	QuietTo   string `json:",omitempty"` // HH:MM until which no reminder is sent
+	Paused    bool
+}
+
+//Validate checks the cadence and the quiet hours of the policy
+func (policy Policy) Validate() error {
+	if policy.First < 0 || policy.Every < 1 || policy.Escalate < 0 {
+		return ErrInvalidPolicy
+	}
+	if (policy.QuietFrom == "") != (policy.QuietTo == "") {
+		return ErrInvalidPolicy
+	}
+	if policy.QuietFrom == "" {
+		return nil
+	}
+	if _, err := ParseClock(policy.QuietFrom); err != nil {
+		return err
+	}
+	_, err := ParseClock(policy.QuietTo)
+	return err
+}
+
+//ParseClock parses the HH:MM time of the day into the minutes since midnight
+func ParseClock(clock string) (int, error) {
+	var hours, minutes int
+	if n, err := fmt.Sscanf(clock, "%d:%d", &hours, &minutes); err != nil || n != 2 {
+		return 0, ErrInvalidPolicy
+	}
+	if hours < 0 || hours > 23 || minutes < 0 || minutes > 59 {
+		return 0, ErrInvalidPolicy
+	}
+	return hours*60 + minutes, nil
+}
+
+//Next returns the time the member is to be reminded next.
+//since is the time of the latest transaction of the member and last is the time of the last reminder, zero if none was sent.
+//A new transaction brings the reminder forward but never pushes the regular one back.
+func (policy Policy) Next(since, last time.Time) time.Time {
+	next := since.AddDate(0, 0, policy.First)
+	if !last.IsZero() {
+		regular := last.AddDate(0, 0, policy.Every)
+		if !last.Before(since) || regular.Before(next) {
+			next = regular
+		}
+	}
+	return policy.afterQuietHours(next)
+}
+
+//Due tells whether the member is to be reminded at now
+func (policy Policy) Due(now, since, last time.Time) bool {
+	return !policy.Paused && !policy.Quiet(now) && !now.Before(policy.Next(since, last))
+}
+
+//Escalated tells whether the reminder following the sent ones copies the creditors in
+func (policy Policy) Escalated(sent int) bool {
+	return policy.Escalate > 0 && sent >= policy.Escalate
+}
+
+//Quiet tells whether the time falls in the quiet hours. Quiet hours can span midnight eg. 22:00 to 07:00.
+func (policy Policy) Quiet(t time.Time) bool {
+	from, to, ok := policy.quietHours()
+	if !ok {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// afterQuietHours moves the time falling in the quiet hours to their end
+func (policy Policy) afterQuietHours(t time.Time) time.Time {
+	if !policy.Quiet(t) {
+		return t
+	}
+	from, to, _ := policy.quietHours()
+	end := time.Date(t.Year(), t.Month(), t.Day(), to/60, to%60, 0, 0, t.Location())
+	if from > to && t.Hour()*60+t.Minute() >= from {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// quietHours returns the minutes of the day the quiet hours start and end at
+func (policy Policy) quietHours() (int, int, bool) {
+	from, err := ParseClock(policy.QuietFrom)
+	if err != nil {
+		return 0, 0, false
+	}
+	to, err := ParseClock(policy.QuietTo)
+	if err != nil || from == to {
+		return 0, 0, false
+	}
+	return from, to, true
+}
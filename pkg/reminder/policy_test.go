@@ -0,0 +1,97 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+)
+
+func at(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestPolicyNext(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy Policy
+		since  string
+		last   string
+		want   string
+	}{
+		{"first reminder", DefaultPolicy, "2026-03-01 10:00", "", "2026-03-04 10:00"},
+		{"weekly after the first", DefaultPolicy, "2026-03-01 10:00", "2026-03-04 10:00", "2026-03-11 10:00"},
+		{"new transaction brings it forward", DefaultPolicy, "2026-03-06 10:00", "2026-03-04 10:00", "2026-03-09 10:00"},
+		{"new transaction doesn't push it back", DefaultPolicy, "2026-03-10 10:00", "2026-03-04 10:00", "2026-03-11 10:00"},
+		{"quiet hours before midnight", Policy{First: 3, Every: 7, QuietFrom: "22:00", QuietTo: "07:30"}, "2026-03-01 23:00", "", "2026-03-05 07:30"},
+		{"quiet hours after midnight", Policy{First: 3, Every: 7, QuietFrom: "22:00", QuietTo: "07:30"}, "2026-03-01 06:00", "", "2026-03-04 07:30"},
+		{"quiet hours within the day", Policy{First: 3, Every: 7, QuietFrom: "12:00", QuietTo: "14:00"}, "2026-03-01 13:00", "", "2026-03-04 14:00"},
+	}
+	for _, c := range cases {
+		var last time.Time
+		if c.last != "" {
+			last = at(c.last)
+		}
+		if got := c.policy.Next(at(c.since), last); !got.Equal(at(c.want)) {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPolicyDue(t *testing.T) {
+	quiet := Policy{First: 3, Every: 7, QuietFrom: "22:00", QuietTo: "07:00"}
+	paused := DefaultPolicy
+	paused.Paused = true
+	cases := []struct {
+		name   string
+		policy Policy
+		now    string
+		want   bool
+	}{
+		{"not yet", DefaultPolicy, "2026-03-04 09:59", false},
+		{"due", DefaultPolicy, "2026-03-04 10:00", true},
+		{"still due later", DefaultPolicy, "2026-03-09 10:00", true},
+		{"quiet", quiet, "2026-03-05 23:00", false},
+		{"after the quiet hours", quiet, "2026-03-06 07:00", true},
+		{"paused", paused, "2026-03-09 10:00", false},
+	}
+	for _, c := range cases {
+		if got := c.policy.Due(at(c.now), at("2026-03-01 10:00"), time.Time{}); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+	if DefaultPolicy.Due(at("2026-03-10 10:00"), at("2026-03-01 10:00"), at("2026-03-04 10:00")) {
+		t.Errorf("reminder is due again before a week")
+	}
+}
+
+func TestPolicyEscalated(t *testing.T) {
+	if DefaultPolicy.Escalated(2) || !DefaultPolicy.Escalated(3) {
+		t.Errorf("expected escalation from the third reminder")
+	}
+	if (Policy{First: 3, Every: 7}).Escalated(10) {
+		t.Errorf("expected no escalation when Escalate is zero")
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	cases := []struct {
+		policy Policy
+		valid  bool
+	}{
+		{DefaultPolicy, true},
+		{Policy{First: 0, Every: 1, QuietFrom: "21:30", QuietTo: "8:00"}, true},
+		{Policy{First: -1, Every: 7}, false},
+		{Policy{First: 3, Every: 0}, false},
+		{Policy{First: 3, Every: 7, QuietFrom: "22:00"}, false},
+		{Policy{First: 3, Every: 7, QuietFrom: "25:00", QuietTo: "07:00"}, false},
+		{Policy{First: 3, Every: 7, QuietFrom: "late", QuietTo: "07:00"}, false},
+	}
+	for _, c := range cases {
+		if err := c.policy.Validate(); (err == nil) != c.valid {
+			t.Errorf("%+v: got %v", c.policy, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package splitter
+
+import (
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+//Balance is the standing of a member over all the shares
+type Balance struct {
+	Memberemail  string
+	Membername   string
+	Paid         money.Money // paid for the bills
+	Share        money.Money // share of the bills
+	Settled      money.Money // paid to the other members minus received from them
+	Net          money.Money // positive when the member gets back, negative when the member owes
+	Transactions int         // number of bills the member paid for or shared
+}
+
+//CreateBalances returns the balance of each member in the order they appear in the shares.
+//Shares paid to a benefactor are counted as settlements between the members, not as bills.
+func CreateBalances(tripId int64, members []Member, shares []Share) []Balance {
+	balances := make([]Balance, 0)
+	index := make(map[string]int)
+	plans := make(map[string]map[[2]int64]bool)
+
+	allShares, _ := mergeDuplicateShares(tripId, 0, members, shares)
+	allShares = populateMemberNameAndAvatar(members, allShares)
+	posShares, negShares := posNegShares(0, allShares)
+	diffs := make(map[string]money.Money)
+	for _, share := range append(posShares, negShares...) {
+		diffs[share.Memberemail] = share.Diff
+	}
+	for _, share := range allShares {
+		index[share.Memberemail] = len(balances)
+		plans[share.Memberemail] = make(map[[2]int64]bool)
+		balances = append(balances, Balance{
+			Memberemail: share.Memberemail,
+			Membername:  share.Membername,
+			Net:         diffs[share.Memberemail],
+		})
+	}
+
+	for _, share := range shares {
+		if isTransfer(share) {
+			continue
+		}
+		balance := &balances[index[share.Memberemail]]
+		balance.Paid = balance.Paid + share.Paid
+		balance.Share = balance.Share + share.Share
+		if share.Paid != 0 || share.Share != 0 {
+			plans[share.Memberemail][[2]int64{share.Tripid, share.Planid}] = true
+		}
+	}
+	for i := range balances {
+		balance := &balances[i]
+		balance.Settled = balance.Net - (balance.Paid - balance.Share)
+		balance.Transactions = len(plans[balance.Memberemail])
+		if balance.Membername == "" {
+			balance.Membername = balance.Memberemail
+		}
+	}
+	return balances
+}
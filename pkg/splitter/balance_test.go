@@ -0,0 +1,39 @@
+package splitter
+
+import (
+	"testing"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+func TestCreateBalances(t *testing.T) {
+	members := []Member{{Email: "gus", Name: "Gus"}, {Email: "walt", Name: "Walt"}, {Email: "jesse", Name: "Jesse"}}
+	shares := []Share{
+		{Tripid: 1, Planid: 1, Memberemail: "gus", Benefactoremail: "gus", Paid: money.FromMinor(9000), Share: money.FromMinor(3000)},
+		{Tripid: 1, Planid: 1, Memberemail: "walt", Benefactoremail: "walt", Share: money.FromMinor(3000)},
+		{Tripid: 1, Planid: 1, Memberemail: "jesse", Benefactoremail: "jesse", Share: money.FromMinor(3000)},
+		{Tripid: 1, Planid: 2, Memberemail: "walt", Benefactoremail: "walt", Paid: money.FromMinor(2000), Share: money.FromMinor(1000)},
+		{Tripid: 1, Planid: 2, Memberemail: "jesse", Benefactoremail: "jesse", Share: money.FromMinor(1000)},
+		{Tripid: 1, Memberemail: "jesse", Benefactoremail: "gus", Paid: money.FromMinor(2500)}, // jesse paid gus back
+	}
+
+	want := []Balance{
+		{Memberemail: "gus", Membername: "Gus", Paid: 9000, Share: 3000, Settled: -2500, Net: 3500, Transactions: 1},
+		{Memberemail: "walt", Membername: "Walt", Paid: 2000, Share: 4000, Settled: 0, Net: -2000, Transactions: 2},
+		{Memberemail: "jesse", Membername: "Jesse", Paid: 0, Share: 4000, Settled: 2500, Net: -1500, Transactions: 2},
+	}
+	balances := CreateBalances(1, members, shares)
+	if len(balances) != len(want) {
+		t.Fatalf("got %+v", balances)
+	}
+	var net money.Money
+	for i := range want {
+		if balances[i] != want[i] {
+			t.Errorf("balance %d = %+v, want %+v", i, balances[i], want[i])
+		}
+		net = net + balances[i].Net
+	}
+	if net != 0 {
+		t.Errorf("balances add up to %s", net)
+	}
+}
@@ -0,0 +1,181 @@
+package splitter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+//DefaultLanguage is the language of the brief when the member has none or it is not in the catalog
+const DefaultLanguage = "en"
+
+//Languages the brief can be written in
+var Languages = []string{"en", "es", "de", "ta"}
+
+//BriefAmounts are the amounts the brief of the current member is written from, kept apart from the sentence
+type BriefAmounts struct {
+	Language string      // language the brief is written in
+	GetsBack money.Money // amount the current member gets back
+	From     []string    // members the current member gets back from
+	Gives    money.Money // amount the current member has to give
+	To       []string    // members the current member has to give to
+}
+
+// plural forms of the CLDR plural rules
+const (
+	pluralOne   = "one"
+	pluralOther = "other"
+)
+
+// messages of a language. Plural messages are keyed by the plural form of the number of members.
+// {amount}, {names} and {count} are replaced when writing the brief.
+type messages struct {
+	settled  string
+	getsBack map[string]string
+	gives    map[string]string
+	and      string
+	plural   func(n int) string
+}
+
+// oneOther is the plural rule of the languages telling one apart from the rest for whole numbers
+func oneOther(n int) string {
+	if n == 1 {
+		return pluralOne
+	}
+	return pluralOther
+}
+
+var catalog = map[string]messages{
+	"en": {
+		settled: "You are settled up.",
+		getsBack: map[string]string{
+			pluralOne:   "You get back {amount} from {names}.",
+			pluralOther: "You get back {amount} from {count} people: {names}.",
+		},
+		gives: map[string]string{
+			pluralOne:   "You have to give {amount} to {names}.",
+			pluralOther: "You have to give {amount} to {count} people: {names}.",
+		},
+		and:    "and",
+		plural: oneOther,
+	},
+	"es": {
+		settled: "Estás al día.",
+		getsBack: map[string]string{
+			pluralOne:   "Recibes {amount} de {names}.",
+			pluralOther: "Recibes {amount} de {count} personas: {names}.",
+		},
+		gives: map[string]string{
+			pluralOne:   "Tienes que dar {amount} a {names}.",
+			pluralOther: "Tienes que dar {amount} a {count} personas: {names}.",
+		},
+		and:    "y",
+		plural: oneOther,
+	},
+	"de": {
+		settled: "Du bist quitt.",
+		getsBack: map[string]string{
+			pluralOne:   "Du bekommst {amount} von {names} zurück.",
+			pluralOther: "Du bekommst {amount} von {count} Personen zurück: {names}.",
+		},
+		gives: map[string]string{
+			pluralOne:   "Du musst {names} {amount} geben.",
+			pluralOther: "Du musst {count} Personen insgesamt {amount} geben: {names}.",
+		},
+		and:    "und",
+		plural: oneOther,
+	},
+	"ta": {
+		settled: "உங்கள் கணக்கு தீர்ந்தது.",
+		getsBack: map[string]string{
+			pluralOne:   "{names} உங்களுக்கு {amount} தர வேண்டும்.",
+			pluralOther: "{count} பேர் உங்களுக்கு மொத்தம் {amount} தர வேண்டும்: {names}.",
+		},
+		gives: map[string]string{
+			pluralOne:   "நீங்கள் {names} அவர்களுக்கு {amount} தர வேண்டும்.",
+			pluralOther: "நீங்கள் {count} பேருக்கு மொத்தம் {amount} தர வேண்டும்: {names}.",
+		},
+		and:    "மற்றும்",
+		plural: oneOther,
+	},
+}
+
+//Language returns the language of the catalog for the tag given as es, de-DE or ta_IN.
+//It is DefaultLanguage and false when the catalog doesn't have the language.
+func Language(tag string) (string, bool) {
+	language := money.BaseLanguage(tag)
+	if _, ok := catalog[language]; ok {
+		return language, true
+	}
+	return DefaultLanguage, false
+}
+
+// briefLanguage is the language given with WithLanguage, else the language of the current member
+func briefLanguage(members []Member, currentMemberEmail string, language string) string {
+	if language != "" {
+		language, _ = Language(language)
+		return language
+	}
+	for _, member := range members {
+		if member.Email == currentMemberEmail {
+			language, _ = Language(member.Language)
+			return language
+		}
+	}
+	return DefaultLanguage
+}
+
+// addCurrentUserBrief writes what the current member gets back and has to give in the language
+func addCurrentUserBrief(planSuggestion *PlanSuggestion, currentMemberEmail string, language string) {
+	ownGetsBackSuggestions, ownGiveSuggestions := splitSuggestionsByGettersAndGivers(planSuggestion, currentMemberEmail)
+	messages := catalog[language]
+	amounts := BriefAmounts{Language: language}
+	for _, suggestion := range ownGetsBackSuggestions {
+		amounts.GetsBack = amounts.GetsBack + suggestion.Amount
+		amounts.From = append(amounts.From, suggestion.BMembername)
+	}
+	for _, suggestion := range ownGiveSuggestions {
+		amounts.Gives = amounts.Gives + suggestion.Amount
+		amounts.To = append(amounts.To, suggestion.AMembername)
+	}
+	planSuggestion.Amounts = amounts
+
+	if amounts.GetsBack == 0 && amounts.Gives == 0 {
+		planSuggestion.Brief = messages.settled
+		planSuggestion.Operation = OpSettled
+		return
+	}
+
+	sentences := make([]string, 0, 2)
+	if amounts.GetsBack > 0 {
+		planSuggestion.Operation = OpGetsBack
+		sentences = append(sentences, messages.sentence(messages.getsBack, amounts.GetsBack, amounts.From, planSuggestion.Currency, language))
+	}
+	if amounts.Gives > 0 {
+		if planSuggestion.Operation == OpGetsBack {
+			planSuggestion.Operation = OpBoth
+		} else {
+			planSuggestion.Operation = OpPaid
+		}
+		sentences = append(sentences, messages.sentence(messages.gives, amounts.Gives, amounts.To, planSuggestion.Currency, language))
+	}
+	planSuggestion.Brief = strings.Join(sentences, " ")
+}
+
+// sentence picks the plural form for the members and fills in the amount and their names
+func (messages messages) sentence(forms map[string]string, amount money.Money, names []string, currency string, language string) string {
+	return strings.NewReplacer(
+		"{amount}", amount.FormatLocale(currency, language),
+		"{count}", strconv.Itoa(len(names)),
+		"{names}", messages.list(names),
+	).Replace(forms[messages.plural(len(names))])
+}
+
+// list joins the names as in a, b and c
+func (messages messages) list(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " " + messages.and + " " + names[len(names)-1]
+}
@@ -0,0 +1,106 @@
+package splitter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+// briefSuggestion is the plan where walt gets back from gus and jesse and gives to hank
+func briefSuggestion(currency string) *PlanSuggestion {
+	suggestion := func(gets, gives string, amount money.Money) Suggestion {
+		return Suggestion{AMemberemail: gets, AMembername: gets, BMemberemail: gives, BMembername: gives, Amount: amount}
+	}
+	return &PlanSuggestion{
+		Currency: currency,
+		Suggestions: []Suggestion{
+			suggestion("walt", "gus", 100000),
+			suggestion("walt", "jesse", 2550),
+			suggestion("hank", "walt", 1000),
+			suggestion("hank", "marie", 500),
+		},
+	}
+}
+
+func TestAddCurrentUserBrief(t *testing.T) {
+	cases := []struct {
+		member    string
+		language  string
+		want      string
+		operation int
+	}{
+		{"walt", "en", "You get back €1,025.50 from 2 people: gus and jesse. You have to give €10.00 to hank.", OpBoth},
+		{"walt", "es", "Recibes 1025,50 € de 2 personas: gus y jesse. Tienes que dar 10,00 € a hank.", OpBoth},
+		{"walt", "de", "Du bekommst 1.025,50 € von 2 Personen zurück: gus und jesse. Du musst hank 10,00 € geben.", OpBoth},
+		{"walt", "ta", "2 பேர் உங்களுக்கு மொத்தம் € 1,025.50 தர வேண்டும்: gus மற்றும் jesse. நீங்கள் hank அவர்களுக்கு € 10.00 தர வேண்டும்.", OpBoth},
+		{"hank", "en", "You get back €15.00 from 2 people: walt and marie.", OpGetsBack},
+		{"gus", "en", "You have to give €1,000.00 to walt.", OpPaid},
+		{"gus", "de", "Du musst walt 1.000,00 € geben.", OpPaid},
+		{"skyler", "en", "You are settled up.", OpSettled},
+		{"skyler", "es", "Estás al día.", OpSettled},
+	}
+	for _, c := range cases {
+		planSuggestion := briefSuggestion("EUR")
+		addCurrentUserBrief(planSuggestion, c.member, c.language)
+		got := strings.Replace(planSuggestion.Brief, "\u00a0", " ", -1)
+		if got != c.want || planSuggestion.Operation != c.operation {
+			t.Errorf("%s in %s: got %q %d, want %q %d", c.member, c.language, got, planSuggestion.Operation, c.want, c.operation)
+		}
+	}
+}
+
+func TestBriefAmounts(t *testing.T) {
+	planSuggestion := briefSuggestion("")
+	addCurrentUserBrief(planSuggestion, "walt", "de")
+	want := BriefAmounts{Language: "de", GetsBack: 102550, From: []string{"gus", "jesse"}, Gives: 1000, To: []string{"hank"}}
+	if !reflect.DeepEqual(planSuggestion.Amounts, want) {
+		t.Errorf("got %+v, want %+v", planSuggestion.Amounts, want)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	cases := []struct {
+		tag   string
+		want  string
+		found bool
+	}{
+		{"es", "es", true},
+		{" de-DE", "de", true},
+		{"TA_in", "ta", true},
+		{"fr", DefaultLanguage, false},
+		{"", DefaultLanguage, false},
+	}
+	for _, c := range cases {
+		if got, found := Language(c.tag); got != c.want || found != c.found {
+			t.Errorf("%q: got %s %t, want %s %t", c.tag, got, found, c.want, c.found)
+		}
+	}
+}
+
+func TestBriefLanguage(t *testing.T) {
+	members := []Member{{Email: "walt", Language: "de-DE"}, {Email: "gus", Language: "fr"}, {Email: "jesse"}}
+	cases := []struct {
+		member string
+		option string
+		want   string
+	}{
+		{"walt", "", "de"},
+		{"walt", "ta_IN", "ta"},
+		{"gus", "", DefaultLanguage},
+		{"jesse", "", DefaultLanguage},
+		{"hank", "ES", "es"},
+	}
+	for _, c := range cases {
+		if got := briefLanguage(members, c.member, c.option); got != c.want {
+			t.Errorf("%s with %q: got %s, want %s", c.member, c.option, got, c.want)
+		}
+	}
+
+	planSuggestion := CreateTotalSuggestion(1, 0, []Member{{Email: "walt", Name: "walt", Language: "es"}, {Email: "gus", Name: "gus"}},
+		"walt", []Share{{Memberemail: "walt", Membername: "walt", Benefactoremail: "walt", Paid: 1000, Share: 500}, {Memberemail: "gus", Membername: "gus", Benefactoremail: "gus", Share: 500}}, WithCurrency("EUR"))
+	if got := strings.Replace(planSuggestion.Brief, "\u00a0", " ", -1); got != "Recibes 5,00 € de gus." {
+		t.Errorf("got %q", got)
+	}
+}
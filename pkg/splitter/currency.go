@@ -0,0 +1,88 @@
+package splitter
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+//ErrCurrencyNeeded is returned when the shares are in different currencies but the currency to settle in is not known
+var ErrCurrencyNeeded = errors.New("Shares are in different currencies, please give the currency to settle in")
+
+//Converter converts the amount between the currencies at the rate effective on the date
+type Converter interface {
+	Convert(amount money.Money, from, to string, date time.Time) (money.Money, error)
+}
+
+type planKey struct {
+	tripid int64
+	planid int64
+}
+
+//ConvertShares converts the shares to the currency at the rate effective on their Created date.
+//Shares without a currency are taken as in the currency already. Shares of a plan are converted together,
+//so the converted paid amounts and shares still add up to the same converted bill.
+func ConvertShares(shares []Share, currency string, converter Converter) ([]Share, error) {
+	converted := make([]Share, len(shares))
+	copy(converted, shares)
+
+	plans := make(map[planKey][]int)
+	order := make([]planKey, 0)
+	for i, share := range shares {
+		if share.Currency == "" || strings.EqualFold(share.Currency, currency) {
+			continue
+		}
+		if currency == "" {
+			return nil, ErrCurrencyNeeded
+		}
+		if share.Planid == 0 { // transfers are converted one by one
+			var err error
+			if converted[i].Paid, err = converter.Convert(share.Paid, share.Currency, currency, share.Created); err != nil {
+				return nil, err
+			}
+			if converted[i].Share, err = converter.Convert(share.Share, share.Currency, currency, share.Created); err != nil {
+				return nil, err
+			}
+			converted[i].Currency = currency
+			continue
+		}
+		key := planKey{share.Tripid, share.Planid}
+		if _, ok := plans[key]; !ok {
+			order = append(order, key)
+		}
+		plans[key] = append(plans[key], i)
+	}
+
+	for _, key := range order {
+		indexes := plans[key]
+		paid := make([]int64, len(indexes))
+		amounts := make([]int64, len(indexes))
+		var paidTotal, shareTotal money.Money
+		for i, index := range indexes {
+			paid[i] = shares[index].Paid.Minor()
+			amounts[i] = shares[index].Share.Minor()
+			paidTotal = paidTotal + shares[index].Paid
+			shareTotal = shareTotal + shares[index].Share
+		}
+
+		first := shares[indexes[0]]
+		convertedPaid, err := converter.Convert(paidTotal, first.Currency, currency, first.Created)
+		if err != nil {
+			return nil, err
+		}
+		convertedShare, err := converter.Convert(shareTotal, first.Currency, currency, first.Created)
+		if err != nil {
+			return nil, err
+		}
+		paidParts := convertedPaid.Allocate(paid)
+		shareParts := convertedShare.Allocate(amounts)
+		for i, index := range indexes {
+			converted[index].Paid = paidParts[i]
+			converted[index].Share = shareParts[i]
+			converted[index].Currency = currency
+		}
+	}
+	return converted, nil
+}
@@ -0,0 +1,77 @@
+package splitter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+// fixedRates converts at a fixed rate per currency pair whatever the date is
+type fixedRates map[string]string
+
+func (rates fixedRates) Convert(amount money.Money, from, to string, date time.Time) (money.Money, error) {
+	value, ok := rates[from+to]
+	if !ok {
+		return 0, money.ErrRateNotFound
+	}
+	rate, err := money.ParseRate(value)
+	if err != nil {
+		return 0, err
+	}
+	return amount.Exchange(rate, from, to), nil
+}
+
+func TestConvertSharesKeepsThePlanBalanced(t *testing.T) {
+	rates := fixedRates{"EURUSD": "1.0833", "JPYUSD": "0.0067"}
+	shares := []Share{
+		{Planid: 1, Memberemail: "gus", Paid: 1000, Share: 334, Currency: "EUR"},
+		{Planid: 1, Memberemail: "walt", Share: 333, Currency: "EUR"},
+		{Planid: 1, Memberemail: "jesse", Share: 333, Currency: "EUR"},
+		{Planid: 2, Memberemail: "walt", Paid: 1000, Share: 500, Currency: "JPY"},
+		{Planid: 2, Memberemail: "gus", Share: 500, Currency: "JPY"},
+		{Planid: 3, Memberemail: "jesse", Paid: 500, Share: 250, Currency: "USD"},
+		{Planid: 3, Memberemail: "gus", Share: 250},
+		{Memberemail: "walt", Benefactoremail: "gus", Paid: 100, Currency: "EUR"},
+	}
+
+	converted, err := ConvertShares(shares, "USD", rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paid := make(map[int64]money.Money)
+	share := make(map[int64]money.Money)
+	for _, s := range converted {
+		if s.Currency != "" && s.Currency != "USD" {
+			t.Errorf("%+v is not converted", s)
+		}
+		paid[s.Planid] += s.Paid
+		share[s.Planid] += s.Share
+	}
+	want := map[int64]money.Money{1: 1083, 2: 670, 3: 500, 0: 108}
+	for planid, amount := range want {
+		if paid[planid] != amount {
+			t.Errorf("plan %d paid %d, want %d", planid, paid[planid], amount)
+		}
+		if planid != 0 && share[planid] != amount {
+			t.Errorf("plan %d shared %d, want %d", planid, share[planid], amount)
+		}
+	}
+	if shares[0].Paid != 1000 || shares[0].Currency != "EUR" {
+		t.Errorf("the given shares are changed: %+v", shares[0])
+	}
+}
+
+func TestConvertSharesNeedsTheCurrency(t *testing.T) {
+	shares := []Share{{Planid: 1, Memberemail: "gus", Paid: 1000, Share: 1000, Currency: "EUR"}}
+	if _, err := ConvertShares(shares, "", fixedRates{}); err != ErrCurrencyNeeded {
+		t.Errorf("got %v, want %v", err, ErrCurrencyNeeded)
+	}
+	if _, err := ConvertShares(shares, "INR", fixedRates{}); err != money.ErrRateNotFound {
+		t.Errorf("got %v, want %v", err, money.ErrRateNotFound)
+	}
+	if _, err := ConvertShares(shares, "eur", fixedRates{}); err != nil {
+		t.Errorf("shares in the currency already: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package splitter
+
+import (
+	"errors"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+var (
+	//ErrItemsNeeded is returned when the itemised split is asked without the items
+	ErrItemsNeeded = errors.New("Itemised split needs the items of the bill")
+	//ErrInvalidItem is returned when an item has a negative price or no quantity
+	ErrInvalidItem = errors.New("Item should have a price and a quantity of atleast one")
+	//ErrItemWithoutMembers is returned when nobody consumed an item
+	ErrItemWithoutMembers = errors.New("Item should be shared by atleast one member")
+)
+
+//Item is a line item of an itemised bill. The members who consumed the item share it equally.
+type Item struct {
+	Name         string
+	Price        money.Money // price of a single unit
+	Quantity     int64
+	Memberemails []string
+}
+
+//Bill is an itemised bill. Tax, service charge and tip are added on top of the items and
+//are distributed in proportion to the subtotal of each member.
+type Bill struct {
+	Items   []Item
+	Tax     money.Money
+	Service money.Money
+	Tip     money.Money
+}
+
+//Cost is the price of all the units of the item
+func (item Item) Cost() money.Money {
+	return item.Price * money.Money(item.Quantity)
+}
+
+//Subtotal is the cost of all the items without tax, service charge and tip
+func (bill Bill) Subtotal() money.Money {
+	var subtotal money.Money
+	for _, item := range bill.Items {
+		subtotal = subtotal + item.Cost()
+	}
+	return subtotal
+}
+
+//Total is the amount of the bill
+func (bill Bill) Total() money.Money {
+	return bill.Subtotal() + bill.Tax + bill.Service + bill.Tip
+}
+
+//SplitItems derives the share of each member from the items of the bill.
+//It returns the members in the order they first appear in the items along with their shares, which add up to the total of the bill.
+func SplitItems(bill Bill, remainder Remainder) ([]string, []money.Money, error) {
+	if len(bill.Items) == 0 {
+		return nil, nil, ErrItemsNeeded
+	}
+
+	emails := make([]string, 0)
+	subtotals := make(map[string]money.Money)
+	for i, item := range bill.Items {
+		if item.Price < 0 || item.Quantity < 1 {
+			return nil, nil, ErrInvalidItem
+		}
+		if len(item.Memberemails) == 0 {
+			return nil, nil, ErrItemWithoutMembers
+		}
+		// each item moves the remainder along so that the same member doesn't carry it every time
+		itemRemainder := remainder
+		itemRemainder.Offset = remainder.Offset + int64(i)
+		itemRemainder.Seed = remainder.Seed + int64(i)
+		parts := SplitEqually(item.Cost(), item.Memberemails, itemRemainder)
+		for j, email := range item.Memberemails {
+			if _, ok := subtotals[email]; !ok {
+				emails = append(emails, email)
+			}
+			subtotals[email] = subtotals[email] + parts[j]
+		}
+	}
+
+	shares := make([]money.Money, len(emails))
+	weights := make([]int64, len(emails))
+	for i, email := range emails {
+		shares[i] = subtotals[email]
+		weights[i] = subtotals[email].Minor()
+	}
+
+	extras := bill.Tax + bill.Service + bill.Tip
+	if extras != 0 {
+		if bill.Subtotal() == 0 { // free items, nothing to be proportional to
+			for i := range weights {
+				weights[i] = 1
+			}
+		}
+		for i, extra := range extras.Allocate(weights) {
+			shares[i] = shares[i] + extra
+		}
+	}
+	return emails, shares, nil
+}
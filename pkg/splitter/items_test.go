@@ -0,0 +1,46 @@
+package splitter
+
+import (
+	"testing"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+func TestSplitItems(t *testing.T) {
+	bill := Bill{
+		Items: []Item{
+			{Name: "Pizza", Price: money.FromMinor(1850), Quantity: 1, Memberemails: []string{"gus", "walt"}},
+			{Name: "Beer", Price: money.FromMinor(400), Quantity: 3, Memberemails: []string{"walt"}},
+			{Name: "Salad", Price: money.FromMinor(975), Quantity: 1, Memberemails: []string{"jesse"}},
+		},
+		Tax:     money.FromMinor(405),
+		Service: money.FromMinor(0),
+		Tip:     money.FromMinor(600),
+	}
+
+	emails, shares, err := SplitItems(bill, Remainder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// subtotals are gus 9.25, walt 21.25 and jesse 9.75 out of 40.25
+	wantEmails := []string{"gus", "walt", "jesse"}
+	wantShares := []money.Money{925 + 231, 2125 + 531, 975 + 243}
+	var total money.Money
+	for i := range wantEmails {
+		if emails[i] != wantEmails[i] || shares[i] != wantShares[i] {
+			t.Errorf("share %d = %s %s, want %s %s", i, emails[i], shares[i], wantEmails[i], wantShares[i])
+		}
+		total = total + shares[i]
+	}
+	if total != bill.Total() {
+		t.Errorf("shares add up to %s, want %s", total, bill.Total())
+	}
+
+	if _, _, err := SplitItems(Bill{Items: []Item{{Name: "Water", Price: 100, Quantity: 1}}}, Remainder{}); err != ErrItemWithoutMembers {
+		t.Errorf("expected ErrItemWithoutMembers, got %v", err)
+	}
+	if _, _, err := SplitItems(Bill{}, Remainder{}); err != ErrItemsNeeded {
+		t.Errorf("expected ErrItemsNeeded, got %v", err)
+	}
+}
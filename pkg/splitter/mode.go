@@ -0,0 +1,136 @@
+package splitter
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+//SplitMode is the way the bill is divided among the members
+type SplitMode int
+
+const (
+	SplitEqual    SplitMode = iota // equal shares, computed by splitUp for the auto shares
+	SplitExact                     // absolute amounts given for each member
+	SplitPercent                   // percentage of the bill in basis points, adding up to 100%
+	SplitWeight                    // units such as nights stayed, the bill is divided in proportion
+	SplitAdjust                    // equal shares plus or minus a delta in minor units for each member
+	SplitItemised                  // shares derived from the line items of the bill, see SplitItems
+)
+
+var splitModeNames = map[SplitMode]string{
+	SplitEqual:    "equal",
+	SplitExact:    "exact",
+	SplitPercent:  "percent",
+	SplitWeight:   "weight",
+	SplitAdjust:   "adjust",
+	SplitItemised: "items",
+}
+
+var (
+	//ErrUnknownSplitMode is returned when the split mode name is not known
+	ErrUnknownSplitMode = errors.New("Split should be one of equal, exact, percent, weight, adjust or items")
+	//ErrWeightsMismatch is returned when the number of weights and shares are different
+	ErrWeightsMismatch = errors.New("Given members and their shares are not matching")
+	//ErrPercentTotal is returned when the percentages don't add up to 100
+	ErrPercentTotal = errors.New("Percentages should add up to 100")
+	//ErrNegativeWeight is returned when a weight is negative or all weights are zero
+	ErrNegativeWeight = errors.New("Weights should not be negative and atleast one should be more than zero")
+	//ErrNegativeShare is returned when an adjustment makes a share negative
+	ErrNegativeShare = errors.New("Adjustments should not make a share negative")
+)
+
+//PercentBasis is the weight of 100% in the percent mode. Percentages are given in basis points.
+const PercentBasis = 10000
+
+//ParseSplitMode returns the split mode for its name. Empty name is the equal split.
+func ParseSplitMode(name string) (SplitMode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return SplitEqual, nil
+	}
+	for mode, modeName := range splitModeNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+	return SplitEqual, ErrUnknownSplitMode
+}
+
+func (mode SplitMode) String() string {
+	return splitModeNames[mode]
+}
+
+//SplitBy resolves the weights of the split mode into absolute shares of the bill, in the order of the shares.
+//Weights are minor units for exact and adjust, basis points for percent and units for weight.
+//The resolved shares are marked manual (Auto false), so that splitUp leaves them untouched.
+//Equal mode marks the shares auto and leaves the split to splitUp.
+func SplitBy(mode SplitMode, billAmount money.Money, shares []Share, weights []int64, remainder Remainder) ([]Share, error) {
+	if mode == SplitEqual {
+		for i := range shares {
+			shares[i].Auto = true
+		}
+		return shares, nil
+	}
+	if len(weights) != len(shares) {
+		return shares, ErrWeightsMismatch
+	}
+
+	var amounts []money.Money
+	switch mode {
+	case SplitExact:
+		amounts = make([]money.Money, len(weights))
+		for i, weight := range weights {
+			amounts[i] = money.FromMinor(weight)
+		}
+	case SplitPercent:
+		var total int64
+		for _, weight := range weights {
+			if weight < 0 {
+				return shares, ErrNegativeWeight
+			}
+			total = total + weight
+		}
+		if total != PercentBasis {
+			return shares, ErrPercentTotal
+		}
+		amounts = billAmount.Allocate(weights)
+	case SplitWeight:
+		var total int64
+		for _, weight := range weights {
+			if weight < 0 {
+				return shares, ErrNegativeWeight
+			}
+			total = total + weight
+		}
+		if total == 0 {
+			return shares, ErrNegativeWeight
+		}
+		amounts = billAmount.Allocate(weights)
+	case SplitAdjust:
+		var adjustments money.Money
+		emails := make([]string, len(shares))
+		for i, weight := range weights {
+			adjustments = adjustments + money.FromMinor(weight)
+			emails[i] = shares[i].Memberemail
+		}
+		amounts = SplitEqually(billAmount-adjustments, emails, remainder.withPayer(shares))
+		for i, weight := range weights {
+			amounts[i] = amounts[i] + money.FromMinor(weight)
+			if amounts[i] < 0 {
+				return shares, ErrNegativeShare
+			}
+		}
+	case SplitItemised:
+		return shares, ErrItemsNeeded
+	default:
+		return shares, ErrUnknownSplitMode
+	}
+
+	for i := range shares {
+		shares[i].Share = amounts[i]
+		shares[i].Auto = false
+	}
+	return shares, nil
+}
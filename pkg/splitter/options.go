@@ -0,0 +1,56 @@
+package splitter
+
+//Option changes the way the suggestions are created
+type Option func(*options)
+
+type options struct {
+	currency  string
+	remainder Remainder
+	solver    Solver
+	allTrips  bool
+	language  string
+}
+
+func newOptions(opts []Option) *options {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+//WithCurrency formats the amounts of the brief with the minor digits of the currency
+func WithCurrency(currency string) Option {
+	return func(options *options) {
+		options.currency = currency
+	}
+}
+
+//WithRemainder uses the remainder policy when the bill is split equally
+func WithRemainder(remainder Remainder) Option {
+	return func(options *options) {
+		options.remainder = remainder
+	}
+}
+
+//WithSolver settles the balances with the solver instead of the greedy one
+func WithSolver(solver Solver) Option {
+	return func(options *options) {
+		options.solver = solver
+	}
+}
+
+//WithAllTrips settles the shares of every trip together, netting the balances of each member across the trips.
+//Each suggestion lists the trips its amount comes from.
+func WithAllTrips() Option {
+	return func(options *options) {
+		options.allTrips = true
+	}
+}
+
+//WithLanguage writes the brief in the language instead of the language of the current member
+func WithLanguage(language string) Option {
+	return func(options *options) {
+		options.language = language
+	}
+}
@@ -0,0 +1,136 @@
+package splitter
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+//RemainderPolicy decides who carries the minor units left when an amount can't be split equally
+type RemainderPolicy int
+
+const (
+	RemainderFirst      RemainderPolicy = iota // first members in the order of the split
+	RemainderPayer                             // the member who paid the bill
+	RemainderRoundRobin                        // rotates over the members from one transaction to the next
+	RemainderRandom                            // random members picked with the seed
+)
+
+var remainderPolicyNames = map[RemainderPolicy]string{
+	RemainderFirst:      "first",
+	RemainderPayer:      "payer",
+	RemainderRoundRobin: "round-robin",
+	RemainderRandom:     "random",
+}
+
+//ErrUnknownRemainderPolicy is returned when the policy name is not known
+var ErrUnknownRemainderPolicy = errors.New("Remainder policy should be one of first, payer, round-robin or random")
+
+//Remainder is the remainder policy along with the details it needs
+type Remainder struct {
+	Policy RemainderPolicy
+	Payer  string // email of the payer. The member who paid the most is used if empty.
+	Offset int64  // position of the transaction in the trip. Used by round robin.
+	Seed   int64  // used by random
+}
+
+//ParseRemainderPolicy returns the policy for its name. Empty name is the first policy.
+func ParseRemainderPolicy(name string) (RemainderPolicy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return RemainderFirst, nil
+	}
+	for policy, policyName := range remainderPolicyNames {
+		if policyName == name {
+			return policy, nil
+		}
+	}
+	return RemainderFirst, ErrUnknownRemainderPolicy
+}
+
+func (policy RemainderPolicy) String() string {
+	return remainderPolicyNames[policy]
+}
+
+//SplitEqually splits the amount equally among the members. The parts add up exactly to the amount,
+//the minor units which can't be split equally are given one each to the members picked by the remainder policy.
+func SplitEqually(amount money.Money, memberEmails []string, remainder Remainder) []money.Money {
+	count := len(memberEmails)
+	parts := make([]money.Money, count)
+	if count == 0 {
+		return parts
+	}
+
+	mean := amount / money.Money(count)
+	left := amount - mean*money.Money(count)
+	unit := money.Money(1)
+	if left < 0 {
+		unit = -1
+	}
+
+	for i := range parts {
+		parts[i] = mean
+	}
+	for _, i := range remainder.order(memberEmails) {
+		if left == 0 {
+			break
+		}
+		parts[i] = parts[i] + unit
+		left = left - unit
+	}
+	return parts
+}
+
+// order returns the index of the members in the order they receive the remainder
+func (remainder Remainder) order(memberEmails []string) []int {
+	count := len(memberEmails)
+	order := make([]int, 0, count)
+	switch remainder.Policy {
+	case RemainderPayer:
+		payer := -1
+		for i, email := range memberEmails {
+			if email == remainder.Payer {
+				payer = i
+				order = append(order, i)
+				break
+			}
+		}
+		for i := range memberEmails {
+			if i != payer {
+				order = append(order, i)
+			}
+		}
+	case RemainderRoundRobin:
+		start := int(remainder.Offset % int64(count))
+		if start < 0 {
+			start = start + count
+		}
+		for i := 0; i < count; i++ {
+			order = append(order, (start+i)%count)
+		}
+	case RemainderRandom:
+		order = rand.New(rand.NewSource(remainder.Seed)).Perm(count)
+	default:
+		for i := range memberEmails {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// withPayer fills the payer of the remainder with the member who paid the most if it is not given
+func (remainder Remainder) withPayer(shares []Share) Remainder {
+	if remainder.Policy != RemainderPayer || remainder.Payer != "" {
+		return remainder
+	}
+	var mostPaid money.Money
+	for _, share := range shares {
+		if share.Paid > mostPaid {
+			mostPaid = share.Paid
+			remainder.Payer = share.Memberemail
+		}
+	}
+	return remainder
+}
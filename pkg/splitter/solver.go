@@ -0,0 +1,154 @@
+package splitter
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+//Solver decides how the balances of the members are settled
+type Solver int
+
+const (
+	SolverGreedy       Solver = iota // the biggest creditor is paid by the biggest debtor until everyone is settled
+	SolverMinTransfers               // the least number of transfers, see ExactSolverLimit
+)
+
+var solverNames = map[Solver]string{
+	SolverGreedy:       "greedy",
+	SolverMinTransfers: "min-transfers",
+}
+
+//ErrUnknownSolver is returned when the solver name is not known
+var ErrUnknownSolver = errors.New("Solver should be either greedy or min-transfers")
+
+//ExactSolverLimit is the number of members with a balance up to which the min transfers solver finds the exact minimum.
+//The search is exponential in the number of members, bigger groups fall back to the greedy solver.
+const ExactSolverLimit = 18
+
+//ParseSolver returns the solver for its name. Empty name is the greedy solver.
+func ParseSolver(name string) (Solver, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return SolverGreedy, nil
+	}
+	for solver, solverName := range solverNames {
+		if solverName == name {
+			return solver, nil
+		}
+	}
+	return SolverGreedy, ErrUnknownSolver
+}
+
+func (solver Solver) String() string {
+	return solverNames[solver]
+}
+
+// settle adds the suggestions which settle the positive shares with the negative shares using the solver
+func settle(posShares []*Share, negShares []*Share, planSuggestion *PlanSuggestion, solver Solver) {
+	if solver == SolverMinTransfers {
+		generateMinTransferSuggestions(posShares, negShares, planSuggestion)
+		return
+	}
+	generateSuggestions(posShares, negShares, planSuggestion)
+}
+
+// generateMinTransferSuggestions partitions the members into the most number of groups whose balances add up to zero.
+// A group of n members settles among themselves with n-1 transfers, so the most groups make the least transfers.
+// Each group is settled with the greedy matcher, which never needs more than n-1 transfers for a zero sum group.
+func generateMinTransferSuggestions(posShares []*Share, negShares []*Share, planSuggestion *PlanSuggestion) {
+	unsettled := make([]*Share, 0, len(posShares)+len(negShares))
+	for _, share := range append(append([]*Share{}, posShares...), negShares...) {
+		if !isTallyed(share.Diff) {
+			unsettled = append(unsettled, share)
+		}
+	}
+	if len(unsettled) > ExactSolverLimit {
+		generateSuggestions(posShares, negShares, planSuggestion)
+		return
+	}
+
+	balances := make([]money.Money, len(unsettled))
+	for i, share := range unsettled {
+		balances[i] = share.Diff
+	}
+	for _, cluster := range zeroSumClusters(balances) {
+		clusterPos := make([]*Share, 0)
+		clusterNeg := make([]*Share, 0)
+		for _, i := range cluster {
+			if unsettled[i].Diff > 0 {
+				clusterPos = append(clusterPos, unsettled[i])
+			} else {
+				clusterNeg = append(clusterNeg, unsettled[i])
+			}
+		}
+		generateSuggestions(clusterPos, clusterNeg, planSuggestion)
+	}
+}
+
+// zeroSumClusters partitions the balances, which add up to zero, into the most number of groups adding up to zero.
+// most[mask] is the most zero sum groups the members in the mask can be ordered into, counting every prefix of
+// the order which adds up to zero. The groups are the members between two such prefixes.
+func zeroSumClusters(balances []money.Money) [][]int {
+	count := len(balances)
+	if count == 0 {
+		return [][]int{}
+	}
+	full := 1<<uint(count) - 1
+	sums := make([]money.Money, full+1)
+	most := make([]int, full+1)
+	for mask := 1; mask <= full; mask++ {
+		for i := 0; i < count; i++ {
+			bit := 1 << uint(i)
+			if mask&bit == 0 {
+				continue
+			}
+			sums[mask] = sums[mask^bit] + balances[i]
+			break
+		}
+		for i := 0; i < count; i++ {
+			bit := 1 << uint(i)
+			if mask&bit != 0 && most[mask^bit] > most[mask] {
+				most[mask] = most[mask^bit]
+			}
+		}
+		if sums[mask] == 0 {
+			most[mask]++
+		}
+	}
+
+	// walk back from all the members, dropping one member at a time without losing a group
+	order := make([]int, 0, count)
+	for mask := full; mask != 0; {
+		target := most[mask]
+		if sums[mask] == 0 {
+			target--
+		}
+		for i := 0; i < count; i++ {
+			bit := 1 << uint(i)
+			if mask&bit != 0 && most[mask^bit] == target {
+				order = append(order, i)
+				mask = mask ^ bit
+				break
+			}
+		}
+	}
+
+	// the members were dropped in the reverse order of adding them
+	clusters := make([][]int, 0, most[full])
+	cluster := make([]int, 0)
+	var sum money.Money
+	for k := len(order) - 1; k >= 0; k-- {
+		cluster = append(cluster, order[k])
+		sum = sum + balances[order[k]]
+		if sum == 0 {
+			clusters = append(clusters, cluster)
+			cluster = make([]int, 0)
+		}
+	}
+	if len(cluster) > 0 { // balances not adding up to zero are left as one group
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
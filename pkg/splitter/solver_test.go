@@ -0,0 +1,122 @@
+package splitter
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+// sharesForBalances creates a member and a share for each balance. Positive balances paid more than their share.
+func sharesForBalances(balances []money.Money) ([]Member, []Share) {
+	members := make([]Member, len(balances))
+	shares := make([]Share, len(balances))
+	for i, balance := range balances {
+		email := "member_" + strconv.Itoa(i)
+		members[i] = Member{Tripid: 1, Email: email, Name: email}
+		shares[i] = Share{Tripid: 1, Memberemail: email, Membername: email, Benefactoremail: email}
+		if balance > 0 {
+			shares[i].Paid = balance
+		} else {
+			shares[i].Share = -balance
+		}
+	}
+	return members, shares
+}
+
+// randomBalances returns count balances adding up to zero
+func randomBalances(random *rand.Rand, count int) []money.Money {
+	balances := make([]money.Money, count)
+	var total money.Money
+	for i := 0; i < count-1; i++ {
+		balances[i] = money.FromMinor(random.Int63n(20001) - 10000)
+		if random.Intn(4) == 0 { // round amounts make zero sum groups more likely
+			balances[i] = balances[i] / 1000 * 1000
+		}
+		total = total + balances[i]
+	}
+	balances[count-1] = -total
+	return balances
+}
+
+// settledBalances applies the transfers of the suggestions on the balances
+func settledBalances(t *testing.T, balances []money.Money, planSuggestion *PlanSuggestion) map[string]money.Money {
+	settled := make(map[string]money.Money)
+	for i, balance := range balances {
+		settled["member_"+strconv.Itoa(i)] = balance
+	}
+	for _, suggestion := range planSuggestion.Suggestions {
+		if suggestion.Amount <= 0 {
+			t.Errorf("transfer of %s from %s to %s", suggestion.Amount, suggestion.BMemberemail, suggestion.AMemberemail)
+		}
+		settled[suggestion.AMemberemail] = settled[suggestion.AMemberemail] - suggestion.Amount
+		settled[suggestion.BMemberemail] = settled[suggestion.BMemberemail] + suggestion.Amount
+	}
+	return settled
+}
+
+func TestSolversZeroAllBalances(t *testing.T) {
+	random := rand.New(rand.NewSource(42))
+	for round := 0; round < 300; round++ {
+		count := 2 + random.Intn(12)
+		if round%50 == 0 {
+			count = ExactSolverLimit + 5 // greedy fallback
+		}
+		balances := randomBalances(random, count)
+
+		unsettled := 0
+		for _, balance := range balances {
+			if balance != 0 {
+				unsettled++
+			}
+		}
+
+		transfers := make(map[Solver]int)
+		for _, solver := range []Solver{SolverGreedy, SolverMinTransfers} {
+			members, shares := sharesForBalances(balances)
+			planSuggestion := CreateTotalSuggestion(1, 0, members, "", shares, WithSolver(solver))
+			for email, balance := range settledBalances(t, balances, planSuggestion) {
+				if balance != 0 {
+					t.Fatalf("%s left %s with %s for %v", solver, email, balance, balances)
+				}
+			}
+			transfers[solver] = len(planSuggestion.Suggestions)
+			if unsettled > 0 && transfers[solver] > unsettled-1 {
+				t.Errorf("%s made %d transfers for %d balances", solver, transfers[solver], unsettled)
+			}
+		}
+		if transfers[SolverMinTransfers] > transfers[SolverGreedy] {
+			t.Errorf("min transfers made %d transfers, greedy made %d for %v", transfers[SolverMinTransfers], transfers[SolverGreedy], balances)
+		}
+	}
+}
+
+func TestMinTransfersBeatsGreedy(t *testing.T) {
+	// greedy pays 3 against -4 first and needs 4 transfers; {3, -3} and {2, 2, -4} need 3
+	balances := []money.Money{200, 200, 300, -300, -400}
+	want := map[Solver]int{SolverGreedy: 4, SolverMinTransfers: 3}
+	for solver, count := range want {
+		members, shares := sharesForBalances(balances)
+		planSuggestion := CreateTotalSuggestion(1, 0, members, "", shares, WithSolver(solver))
+		if len(planSuggestion.Suggestions) != count {
+			t.Errorf("%s made %d transfers, want %d", solver, len(planSuggestion.Suggestions), count)
+		}
+	}
+}
+
+func TestZeroSumClusters(t *testing.T) {
+	clusters := zeroSumClusters([]money.Money{500, -200, 300, -300, -300})
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %v", clusters)
+	}
+	for _, cluster := range clusters {
+		var sum money.Money
+		for _, i := range cluster {
+			sum = sum + []money.Money{500, -200, 300, -300, -300}[i]
+		}
+		if sum != 0 {
+			t.Errorf("cluster %v adds up to %s", cluster, sum)
+		}
+	}
+}
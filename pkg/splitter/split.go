@@ -3,19 +3,20 @@ package splitter
 import (
 	"encoding/json"
 	"log"
-	"math"
-	"strconv"
 	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
 )
 
 //Member is the user who involved in the expense
 type Member struct {
-	Tripid  int64
-	Name    string
-	Email   string
-	Avatar  string
-	Created time.Time
-	Updated int64
+	Tripid   int64
+	Name     string
+	Email    string
+	Avatar   string
+	Language string `json:",omitempty"` // language of the brief written for the member, see Languages
+	Created  time.Time
+	Updated  int64
 }
 
 // Share the member has to pay
@@ -28,10 +29,11 @@ type Share struct {
 	Memberavatar    string
 	Benefactoremail string //The amount paid by this Memberid to this Benefactorid.
 	Note            string
-	Paid            float64 //Amount paid by this member.
-	Share           float64 //Actual share he has to pay.
-	Diff            float64 //Used internally to create suggestions
+	Paid            money.Money //Amount paid by this member.
+	Share           money.Money //Actual share he has to pay.
+	Diff            money.Money //Used internally to create suggestions
 	Auto            bool
+	Currency        string // Currency of Paid and Share, see ConvertShares
 	Created         time.Time
 	Updated         int64
 }
@@ -42,8 +44,10 @@ type PlanSuggestion struct {
 	Planid      int64
 	Notes       string
 	Brief       string
+	Amounts     BriefAmounts // amounts the brief is written from
 	Date        string
-	Amount      float64
+	Currency    string // Currency of the amounts. Used only for formatting the brief.
+	Amount      money.Money
 	Operation   int
 	Suggestions []Suggestion
 }
@@ -56,9 +60,16 @@ type Suggestion struct {
 	BMemberemail  string
 	BMembername   string
 	BMemberavatar string
-	Amount        float64
+	Amount        money.Money
 	Operation     int
 	Datestr       string
+	Trips         []TripAmount // trips the amount comes from when the trips are settled together, see WithAllTrips
+}
+
+//TripAmount is the part of a suggestion which comes from the trip
+type TripAmount struct {
+	Tripid int64
+	Amount money.Money
 }
 
 const (
@@ -83,9 +94,61 @@ func SplitSharesForBillWrapper(tripId int64, planId int64, billAmount float64, c
 		return "", 0, false
 	}
 
-	allShares, meanShare, isEquallySplit := splitSharesForBill(tripId, planId, billAmount, currentMemberEmail, members, shares)
+	allShares, meanShare, isEquallySplit := splitSharesForBill(tripId, planId, money.FromFloat(billAmount), currentMemberEmail, members, shares, Remainder{})
 	allSharesJson, _ := json.Marshal(allShares)
-	return string(allSharesJson), meanShare, isEquallySplit
+	return string(allSharesJson), meanShare.Float64(), isEquallySplit
+}
+
+//SplitSharesForBillJSON is SplitSharesForBillWrapper returning the problems instead of logging them.
+//The error is a JSONError when the members or shares can't be decoded, otherwise a ValidationResult listing
+//unknown members, negative amounts and the payments or manual shares which add up to more than the bill.
+func SplitSharesForBillJSON(tripId int64, planId int64, billAmount float64, currentMemberEmail string, membersJson string, sharesJson string) (string, float64, bool, error) {
+	members, err := decodeMembers(membersJson)
+	if err != nil {
+		return "", 0, false, err
+	}
+	shares, err := decodeShares(sharesJson)
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	bill := money.FromFloat(billAmount)
+	result := validateAmounts(planId, bill, members, shares)
+	var totalAmountPaid, manualShare money.Money
+	for _, share := range shares {
+		if !isCurrentPlan(share.Planid, planId) {
+			continue
+		}
+		if !isTransfer(share) {
+			totalAmountPaid = totalAmountPaid + share.Paid
+		}
+		if !share.Auto {
+			manualShare = manualShare + share.Share
+		}
+	}
+	if totalAmountPaid > bill {
+		result.add(&TotalsMismatchError{Total: TotalPaid, Amount: totalAmountPaid, Bill: bill})
+	}
+	if manualShare > bill {
+		result.add(&TotalsMismatchError{Total: TotalShare, Amount: manualShare, Bill: bill})
+	}
+	if err := result.Err(); err != nil {
+		return "", 0, false, err
+	}
+
+	allShares, meanShare, isEquallySplit := splitSharesForBill(tripId, planId, bill, currentMemberEmail, members, shares, Remainder{})
+	allSharesJson, err := marshalShares(allShares)
+	if err != nil {
+		return "", 0, false, err
+	}
+	return allSharesJson, meanShare.Float64(), isEquallySplit, nil
+}
+
+// SplitSharesForBill calculates the share for each member in the group for the specific bill paid.
+// See splitSharesForBill for the details. WithRemainder changes who carries the remainder of the equal split.
+func SplitSharesForBill(tripId int64, planId int64, billAmount money.Money, currentMemberEmail string, members []Member, shares []Share, opts ...Option) ([]Share, money.Money, bool) {
+	options := newOptions(opts)
+	return splitSharesForBill(tripId, planId, billAmount, currentMemberEmail, members, shares, options.remainder)
 }
 
 // Calculate the share for each member in the group for the specific bill paid.
@@ -96,13 +159,14 @@ func SplitSharesForBillWrapper(tripId int64, planId int64, billAmount float64, c
 // shares - Already calculated share list. Empty otherwise. Share item inside share has a field called isFresh,
 // make sure to mark that field false if you changes the share manually
 // billAmount - Total bill amount for the expense made.
-func splitSharesForBill(tripId int64, planId int64, billAmount float64, currentMemberEmail string, members []Member, shares []Share) ([]Share, float64, bool) {
+// remainder - Decides who carries the minor units left after splitting equally.
+func splitSharesForBill(tripId int64, planId int64, billAmount money.Money, currentMemberEmail string, members []Member, shares []Share, remainder Remainder) ([]Share, money.Money, bool) {
 	// shares might have multiple values for the same memberid If he paid the amount in stages.
 	allShares, sharesPresentAlready := mergeDuplicateShares(tripId, planId, members, shares)
 	// create/update shares from member name and avatar if not present already
 	allShares = createShares(tripId, planId, members, allShares, sharesPresentAlready)
 	// make the members who are not auto in the shares split equally.
-	allShares, meanShare, isEquallySplit := splitUp(currentMemberEmail, allShares, billAmount)
+	allShares, meanShare, isEquallySplit := splitUp(currentMemberEmail, allShares, billAmount, remainder)
 
 	return allShares, meanShare, isEquallySplit
 }
@@ -114,8 +178,9 @@ func mergeDuplicateShares(tripId int64, planId int64, members []Member, sharesWi
 	// creating new shares out of benefactor
 	shares := createSharesOutOfBenefactor(planId, members, sharesWithDuplicates)
 
-	//merging shares by memberid
+	//merging shares by memberid. order keeps the members in the order they are seen so that the result is deterministic.
 	memberMap := make(map[string]*Share)
+	order := make([]string, 0)
 	for i := 0; i < len(shares); i++ {
 		share := shares[i]
 		// allow zero planid for calculating total shares
@@ -130,6 +195,7 @@ func mergeDuplicateShares(tripId int64, planId int64, members []Member, sharesWi
 				savedShare.Share = savedShare.Share + share.Share
 			} else {
 				memberMap[share.Memberemail] = &share
+				order = append(order, share.Memberemail)
 			}
 		}
 	}
@@ -138,7 +204,8 @@ func mergeDuplicateShares(tripId int64, planId int64, members []Member, sharesWi
 	allShares := make([]Share, 0)
 	// find whether there are shares already present in the db
 	sharesPresentAlready := false
-	for _, share := range memberMap {
+	for _, memberEmail := range order {
+		share := memberMap[memberEmail]
 		if share.Id != 0 {
 			sharesPresentAlready = true
 		}
@@ -199,11 +266,16 @@ func createShares(tripId int64, planId int64, members []Member, shares []Share,
 // 3) If the share of the anyone/multiple user/users changes, others shares will be modified only if the auto is true
 // 4) If the bill amount changes, needs manual correction
 // 5) If new members added after the original bill, he will not be added to the old bill unless manual changes.
-func splitUp(currentMemberEmail string, shares []Share, billAmount float64) ([]Share, float64, bool) {
-	var totalAmountPaid float64 = 0
+// The shares always add up exactly to the bill amount. The minor units which can't be split equally are
+// added to the auto shares picked by the remainder policy, so those shares may be one minor unit more than the mean share.
+func splitUp(currentMemberEmail string, shares []Share, billAmount money.Money, remainder Remainder) ([]Share, money.Money, bool) {
+	var totalAmountPaid money.Money = 0
 	isEquallySplit := true
 	memberCount := len(shares)
-	meanShare := billAmount / float64(memberCount)
+	var meanShare money.Money
+	if memberCount > 0 {
+		meanShare = billAmount / money.Money(memberCount)
+	}
 
 	// before calculation remove manually added shares from the splitup calculation
 	for i := 0; i < len(shares); i++ {
@@ -218,8 +290,15 @@ func splitUp(currentMemberEmail string, shares []Share, billAmount float64) ([]S
 	}
 
 	// calculate autoShare
+	autoEmails := make([]string, 0, memberCount)
+	for _, share := range shares {
+		if share.Auto {
+			autoEmails = append(autoEmails, share.Memberemail)
+		}
+	}
+	autoShares := SplitEqually(billAmount, autoEmails, remainder.withPayer(shares))
 	if memberCount > 0 {
-		meanShare = billAmount / float64(memberCount)
+		meanShare = billAmount / money.Money(memberCount)
 	}
 
 	// loop again to set the meanshare
@@ -227,7 +306,7 @@ func splitUp(currentMemberEmail string, shares []Share, billAmount float64) ([]S
 		share := &shares[i]
 
 		if share.Auto { // which means, he has been added automatically by looping members
-			share.Share = meanShare
+			share.Share, autoShares = autoShares[0], autoShares[1:]
 
 			// make current user pay by default
 			if share.Memberemail == currentMemberEmail && share.Paid == 0 {
@@ -235,7 +314,8 @@ func splitUp(currentMemberEmail string, shares []Share, billAmount float64) ([]S
 			}
 		}
 
-		if preciselyTwo(share.Share) != preciselyTwo(meanShare) {
+		// the remainder of the split makes a share differ by one minor unit at most
+		if (share.Share - meanShare).Abs() > 1 {
 			isEquallySplit = false
 		}
 	}
@@ -243,41 +323,6 @@ func splitUp(currentMemberEmail string, shares []Share, billAmount float64) ([]S
 	return shares, meanShare, isEquallySplit
 }
 
-func ValidateShares(sharesJson string, billAmount float64) (bool, string) {
-	shares, err := parseShares(sharesJson)
-	if err != nil {
-		log.Println("Error while decoding sharesJson ", err)
-		return false, "Error while decoding sharesJson"
-	}
-
-	totalAmountPaid := 0.0
-	totalShare := 0.0
-	for _, share := range shares {
-		totalAmountPaid = totalAmountPaid + share.Paid
-		totalShare = totalShare + share.Share
-	}
-
-	totalAmountPaid = preciselyTwo(totalAmountPaid)
-	totalShare = preciselyTwo(totalShare)
-	billAmount = preciselyTwo(billAmount)
-
-	// check if total amount paid is in par with the expense
-	if totalAmountPaid > billAmount {
-		return false, "Total amount paid more than the bill amount"
-	} else if totalAmountPaid < billAmount {
-		return false, "Total amount paid less than the bill amount"
-	}
-
-	// check if total amount shared is in par with the expense
-	if totalShare > billAmount {
-		return false, "Total share is more than the bill amount"
-	} else if totalShare < billAmount {
-		return false, "Total share is less than the bill amount"
-	}
-
-	return true, ""
-}
-
 func getMember(members []Member, memberEmail string) Member {
 	for _, member := range members {
 		if member.Email == memberEmail {
@@ -330,18 +375,8 @@ func formatTimeMonth(t time.Time) string {
 	return t.Format(monthLayout)
 }
 
-func preciselyTwo(num float64) float64 {
-	precision := 2
-	output := math.Pow(10, float64(precision))
-	return float64(round(num*output)) / output
-}
-
-func round(num float64) int {
-	return int(num + math.Copysign(0.5, num))
-}
-
-func isTallyed(someValue float64) bool {
-	if preciselyTwo(someValue) == 0 {
+func isTallyed(someValue money.Money) bool {
+	if someValue == 0 {
 		return true
 	}
 	return false
@@ -354,10 +389,6 @@ func isCurrentPlan(planId, currentPlanId int64) bool {
 	return false
 }
 
-func float64Str(id float64) string {
-	return strconv.FormatFloat(id, 'f', 2, 64)
-}
-
 func parseMembers(response string) ([]Member, error) {
 	var obj []Member
 	err := json.Unmarshal([]byte(response), &obj)
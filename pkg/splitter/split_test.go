@@ -1 +1,132 @@
 package splitter
+
+import (
+	"testing"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+func TestSplitSharesForBillAddsUpToTheBill(t *testing.T) {
+	members := createDummyMembers()
+
+	shares, meanShare, isEquallySplit := splitSharesForBill(1, 1, money.FromMinor(100000), "", members, []Share{}, Remainder{})
+	if !isEquallySplit {
+		t.Errorf("expected the bill to be split equally")
+	}
+	if meanShare != 33333 {
+		t.Errorf("expected mean share 333.33, got %s", meanShare)
+	}
+
+	var total money.Money
+	for _, share := range shares {
+		total = total + share.Share
+	}
+	if total != 100000 {
+		t.Errorf("shares add up to %s instead of 1000.00", total)
+	}
+	if shares[0].Share != 33334 {
+		t.Errorf("expected the first member to carry the remainder, got %s", shares[0].Share)
+	}
+}
+
+func TestSplitEquallyRemainderPolicies(t *testing.T) {
+	emails := []string{"gus", "walt", "jesse"}
+	amount := money.FromMinor(200) // 2.00 leaves two cents after 0.66 each
+
+	cases := []struct {
+		remainder Remainder
+		want      []money.Money
+	}{
+		{Remainder{Policy: RemainderFirst}, []money.Money{67, 67, 66}},
+		{Remainder{Policy: RemainderPayer, Payer: "jesse"}, []money.Money{67, 66, 67}},
+		{Remainder{Policy: RemainderRoundRobin, Offset: 2}, []money.Money{67, 66, 67}},
+		{Remainder{Policy: RemainderRoundRobin, Offset: 4}, []money.Money{66, 67, 67}},
+	}
+	for _, c := range cases {
+		parts := SplitEqually(amount, emails, c.remainder)
+		for i := range parts {
+			if parts[i] != c.want[i] {
+				t.Errorf("%s: got %v, want %v", c.remainder.Policy, parts, c.want)
+				break
+			}
+		}
+	}
+
+	first := SplitEqually(amount, emails, Remainder{Policy: RemainderRandom, Seed: 42})
+	second := SplitEqually(amount, emails, Remainder{Policy: RemainderRandom, Seed: 42})
+	var total money.Money
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("random split is not repeatable with the same seed: %v %v", first, second)
+		}
+		total = total + first[i]
+	}
+	if total != amount {
+		t.Errorf("random split adds up to %s", total)
+	}
+}
+
+func TestSplitByModes(t *testing.T) {
+	bill := money.FromMinor(10000)
+
+	cases := []struct {
+		mode    SplitMode
+		weights []int64
+		want    []money.Money
+	}{
+		{SplitPercent, []int64{5000, 3000, 2000}, []money.Money{5000, 3000, 2000}},
+		{SplitPercent, []int64{3333, 3333, 3334}, []money.Money{3333, 3333, 3334}},
+		{SplitWeight, []int64{2, 3, 3}, []money.Money{2500, 3750, 3750}},
+		{SplitWeight, []int64{1, 1, 1}, []money.Money{3334, 3333, 3333}},
+		{SplitAdjust, []int64{0, 1000, -1000}, []money.Money{3334, 4333, 2333}},
+	}
+	for _, c := range cases {
+		shares, err := SplitBy(c.mode, bill, createDummyShares(), c.weights, Remainder{})
+		if err != nil {
+			t.Fatalf("%s: %v", c.mode, err)
+		}
+		var total money.Money
+		for i, share := range shares {
+			if share.Share != c.want[i] {
+				t.Errorf("%s %v: share %d = %s, want %s", c.mode, c.weights, i, share.Share, c.want[i])
+			}
+			if share.Auto {
+				t.Errorf("%s: resolved share should not be auto", c.mode)
+			}
+			total = total + share.Share
+		}
+		if total != bill {
+			t.Errorf("%s %v: shares add up to %s", c.mode, c.weights, total)
+		}
+	}
+
+	if _, err := SplitBy(SplitPercent, bill, createDummyShares(), []int64{5000, 3000, 1000}, Remainder{}); err != ErrPercentTotal {
+		t.Errorf("expected ErrPercentTotal, got %v", err)
+	}
+	if _, err := SplitBy(SplitAdjust, bill, createDummyShares(), []int64{0, 0, -6000}, Remainder{}); err != ErrNegativeShare {
+		t.Errorf("expected ErrNegativeShare, got %v", err)
+	}
+}
+
+func TestSplitByKeepsManualSharesOutOfTheEqualSplit(t *testing.T) {
+	members := createDummyMembers()
+	shares := make([]Share, 0)
+	for _, member := range members {
+		shares = append(shares, Share{Memberemail: member.Email, Benefactoremail: member.Email})
+	}
+
+	// first two members split 60% of 100.00 by weight, the last one takes the rest equally
+	resolved, err := SplitBy(SplitWeight, money.FromMinor(6000), shares[:2], []int64{1, 2}, Remainder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares = append(resolved, Share{Memberemail: members[2].Email, Benefactoremail: members[2].Email, Auto: true})
+
+	allShares, _, _ := splitSharesForBill(0, 0, money.FromMinor(10000), "", members, shares, Remainder{})
+	want := []money.Money{2000, 4000, 4000}
+	for i, share := range allShares {
+		if share.Share != want[i] {
+			t.Errorf("share of %s = %s, want %s", share.Memberemail, share.Share, want[i])
+		}
+	}
+}
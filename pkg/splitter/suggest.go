@@ -1,9 +1,11 @@
 package splitter
 
 import (
+	"container/heap"
 	"log"
-	"sort"
 	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
 )
 
 func CreateTotalSuggestionWrapper(tripId int64, totalAmount float64, membersJson string, currentMemberEmail string, sharesJson string) *PlanSuggestion {
@@ -19,7 +21,7 @@ func CreateTotalSuggestionWrapper(tripId int64, totalAmount float64, membersJson
 		return &PlanSuggestion{}
 	}
 
-	return CreateTotalSuggestion(tripId, totalAmount, members, currentMemberEmail, shares)
+	return CreateTotalSuggestion(tripId, money.FromFloat(totalAmount), members, currentMemberEmail, shares)
 }
 
 func CreateIndividualSuggestionWrapper(tripId, planId int64, amount float64, notes string, created time.Time, membersJson string, currentMemberEmail string, sharesJson string) *PlanSuggestion {
@@ -35,16 +37,70 @@ func CreateIndividualSuggestionWrapper(tripId, planId int64, amount float64, not
 		log.Println("Error while decoding sharesJson ", err)
 		return &PlanSuggestion{}
 	}
-	return createIndividualSuggestion(tripId, planId, amount, notes, created, members, currentMemberEmail, shares)
+	return createIndividualSuggestion(tripId, planId, money.FromFloat(amount), notes, created, members, currentMemberEmail, shares)
+}
+
+//CreateTotalSuggestionJSON is CreateTotalSuggestionWrapper returning the problems instead of logging them.
+//The error is a JSONError when the members or shares can't be decoded, otherwise a ValidationResult listing
+//unknown members, negative amounts and the totals which are not the total amount.
+func CreateTotalSuggestionJSON(tripId int64, totalAmount float64, membersJson string, currentMemberEmail string, sharesJson string) (*PlanSuggestion, error) {
+	members, shares, err := decodeSuggestionInput(membersJson, sharesJson)
+	if err != nil {
+		return nil, err
+	}
+	amount := money.FromFloat(totalAmount)
+	if err := validateSuggestionInput(0, amount, members, shares); err != nil {
+		return nil, err
+	}
+	return CreateTotalSuggestion(tripId, amount, members, currentMemberEmail, shares), nil
+}
+
+//CreateIndividualSuggestionJSON is CreateIndividualSuggestionWrapper returning the problems instead of logging them.
+//The shares of the plan are validated against the amount like CreateTotalSuggestionJSON.
+func CreateIndividualSuggestionJSON(tripId, planId int64, amount float64, notes string, created time.Time, membersJson string, currentMemberEmail string, sharesJson string) (*PlanSuggestion, error) {
+	members, shares, err := decodeSuggestionInput(membersJson, sharesJson)
+	if err != nil {
+		return nil, err
+	}
+	bill := money.FromFloat(amount)
+	if err := validateSuggestionInput(planId, bill, members, shares); err != nil {
+		return nil, err
+	}
+	return createIndividualSuggestion(tripId, planId, bill, notes, created, members, currentMemberEmail, shares), nil
 }
 
-func CreateTotalSuggestion(tripId int64, totalAmount float64, members []Member, currentMemberEmail string, shares []Share) *PlanSuggestion {
+func decodeSuggestionInput(membersJson string, sharesJson string) ([]Member, []Share, error) {
+	members, err := decodeMembers(membersJson)
+	if err != nil {
+		return nil, nil, err
+	}
+	shares, err := decodeShares(sharesJson)
+	if err != nil {
+		return nil, nil, err
+	}
+	return members, shares, nil
+}
+
+// validateSuggestionInput makes sure the shares of the plan add up to the amount, zero planId is every plan
+func validateSuggestionInput(planId int64, amount money.Money, members []Member, shares []Share) error {
+	planShares := make([]Share, 0, len(shares))
+	for _, share := range shares {
+		if isCurrentPlan(share.Planid, planId) {
+			planShares = append(planShares, share)
+		}
+	}
+	return ValidateSharesForBill(amount, members, planShares).Err()
+}
+
+func CreateTotalSuggestion(tripId int64, totalAmount money.Money, members []Member, currentMemberEmail string, shares []Share, opts ...Option) *PlanSuggestion {
+	options := newOptions(opts)
 	planSuggestion := &PlanSuggestion{
 		Tripid:      tripId,
 		Planid:      0,
 		Notes:       "Total",
 		Brief:       "",
 		Date:        "--",
+		Currency:    options.currency,
 		Amount:      totalAmount,
 		Operation:   OpNotInvolved,
 		Suggestions: make([]Suggestion, 0),
@@ -54,12 +110,15 @@ func CreateTotalSuggestion(tripId int64, totalAmount float64, members []Member,
 	// create/update shares from member name and avatar if not present already
 	allShares = createShares(tripId, 0, members, allShares, sharesPresentAlready)
 	posShares, negShares := posNegShares(0, allShares)
-	generateSuggestions(posShares, negShares, planSuggestion)
-	addCurrentUserBrief(planSuggestion, currentMemberEmail)
+	settle(posShares, negShares, planSuggestion, options.solver)
+	if options.allTrips {
+		attributeTrips(members, shares, planSuggestion.Suggestions)
+	}
+	addCurrentUserBrief(planSuggestion, currentMemberEmail, briefLanguage(members, currentMemberEmail, options.language))
 	return planSuggestion
 }
 
-func createIndividualSuggestion(tripId, planId int64, amount float64, notes string, created time.Time, members []Member, currentMemberEmail string, shares []Share) *PlanSuggestion {
+func createIndividualSuggestion(tripId, planId int64, amount money.Money, notes string, created time.Time, members []Member, currentMemberEmail string, shares []Share) *PlanSuggestion {
 	planSuggestion := &PlanSuggestion{
 		Tripid:      tripId,
 		Planid:      planId,
@@ -80,7 +139,7 @@ func createIndividualSuggestion(tripId, planId int64, amount float64, notes stri
 		allShares = createShares(tripId, planId, members, allShares, sharesPresentAlready)
 		posShares, negShares := posNegShares(planId, allShares)
 		generateSuggestions(posShares, negShares, planSuggestion)
-		addCurrentUserBrief(planSuggestion, currentMemberEmail)
+		addCurrentUserBrief(planSuggestion, currentMemberEmail, briefLanguage(members, currentMemberEmail, ""))
 	}
 	return planSuggestion
 }
@@ -131,62 +190,85 @@ func populateMemberNameAndAvatar(members []Member, shares []Share) []Share {
 	return shares
 }
 
-// Generate suggestions is a recursive funtion. It calls the same function if the positive share is greater than zero.
-// It breaks on the event of share's positive diff == 0.
-// Each call has to sort the shares again to suggest big amounts of the current set.
+// generateSuggestions matches the biggest creditor with the biggest debtor, suggests the smaller of the two balances
+// and repeats until everyone is settled. Creditors and debtors are kept in heaps, so that the biggest balances are
+// found without sorting the shares again after every match. Equal balances are matched in the order of the shares.
 func generateSuggestions(posShares []*Share, negShares []*Share, planSuggestion *PlanSuggestion) {
+	creditors := newBalanceHeap(posShares, 1)
+	debtors := newBalanceHeap(negShares, -1)
+
+	for creditors.Len() > 0 && debtors.Len() > 0 {
+		positiveShare := creditors.entries[0].share
+		negativeShare := debtors.entries[0].share
+
+		if positiveShare.Diff >= -negativeShare.Diff {
+			suggestion := createSuggestion(-negativeShare.Diff, positiveShare, negativeShare)
+			planSuggestion.Suggestions = append(planSuggestion.Suggestions, suggestion)
+			positiveShare.Diff = positiveShare.Diff + negativeShare.Diff
+			negativeShare.Diff = 0
+		} else {
+			suggestion := createSuggestion(positiveShare.Diff, positiveShare, negativeShare)
+			planSuggestion.Suggestions = append(planSuggestion.Suggestions, suggestion)
+			negativeShare.Diff = negativeShare.Diff + positiveShare.Diff
+			positiveShare.Diff = 0
+		}
+		creditors.update()
+		debtors.update()
+	}
+}
 
-	//sort suggestions - so that big payer should tally big defaulter
-	sort.Slice(posShares, func(i, j int) bool { return posShares[i].Diff > posShares[j].Diff })
-	sort.Slice(negShares, func(i, j int) bool { return negShares[i].Diff < negShares[j].Diff })
+// balanceHeap keeps the share with the biggest balance on the top. sign is 1 for the creditors and -1 for the debtors.
+type balanceHeap struct {
+	entries []balanceEntry
+	sign    money.Money
+}
 
-	for i := 0; i < len(posShares); i++ {
-		positiveShare := posShares[i]
-		//fmt.Printf("positiveShare %+v\n", *positiveShare)
+type balanceEntry struct {
+	share *Share
+	index int // position in the given shares, breaks the ties
+}
 
-		// Already settled
-		if isTallyed(positiveShare.Diff) { // If need add this too.
-			// suggestion := createSuggestion(0, positiveShare, nil)
-			// planSuggestion.Suggestions = append(planSuggestion.Suggestions, suggestion)
-			// log.Println("suggestion suggestion  ", suggestion)
-			continue
+func newBalanceHeap(shares []*Share, sign money.Money) *balanceHeap {
+	h := &balanceHeap{entries: make([]balanceEntry, 0, len(shares)), sign: sign}
+	for i, share := range shares {
+		if !isTallyed(share.Diff) {
+			h.entries = append(h.entries, balanceEntry{share: share, index: i})
 		}
+	}
+	heap.Init(h)
+	return h
+}
 
-		for j := 0; j < len(negShares); j++ {
-			negativeShare := negShares[j]
-			//fmt.Printf("negativeShare %+v\n", *negativeShare)
-
-			if isTallyed(negativeShare.Diff) {
-				continue
-			}
+// update drops the top share once it is tallyed or moves it down to its place with the balance left
+func (h *balanceHeap) update() {
+	if isTallyed(h.entries[0].share.Diff) {
+		heap.Pop(h)
+	} else {
+		heap.Fix(h, 0)
+	}
+}
 
-			if positiveShare.Diff >= -negativeShare.Diff {
-				suggestion := createSuggestion(-negativeShare.Diff, positiveShare, negativeShare)
-				planSuggestion.Suggestions = append(planSuggestion.Suggestions, suggestion)
-				positiveShare.Diff = positiveShare.Diff + negativeShare.Diff
-				negativeShare.Diff = 0
-				//fmt.Printf("positive suggestion %+v\n", suggestion)
-				generateSuggestions(posShares, negShares, planSuggestion)
-				return
-			} else {
-				suggestion := createSuggestion(positiveShare.Diff, positiveShare, negativeShare)
-				planSuggestion.Suggestions = append(planSuggestion.Suggestions, suggestion)
-				negativeShare.Diff = negativeShare.Diff + positiveShare.Diff
-				positiveShare.Diff = 0
-				//fmt.Printf("negative suggestion %+v\n", suggestion)
-				generateSuggestions(posShares, negShares, planSuggestion)
-				return
-			}
+func (h *balanceHeap) Len() int { return len(h.entries) }
 
-			if isTallyed(positiveShare.Diff) {
-				break
-			}
-		}
+func (h *balanceHeap) Less(i, j int) bool {
+	a, b := h.entries[i].share.Diff*h.sign, h.entries[j].share.Diff*h.sign
+	if a != b {
+		return a > b
 	}
+	return h.entries[i].index < h.entries[j].index
+}
+
+func (h *balanceHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
 
+func (h *balanceHeap) Push(x interface{}) { h.entries = append(h.entries, x.(balanceEntry)) }
+
+func (h *balanceHeap) Pop() interface{} {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
 }
 
-func createSuggestion(payableAmount float64, positiveShare *Share, negativeShare *Share) Suggestion {
+func createSuggestion(payableAmount money.Money, positiveShare *Share, negativeShare *Share) Suggestion {
 	operation := OpGetsBack
 	if payableAmount == 0 {
 		operation = OpSettled
@@ -234,37 +316,6 @@ func createBenefactorSuggestion(payer *Share, members []Member) Suggestion {
 	return suggestion
 }
 
-func addCurrentUserBrief(planSuggestion *PlanSuggestion, currentMemberEmail string) {
-	ownGetsBackSuggestions, ownGiveSuggestions := splitSuggestionsByGettersAndGivers(planSuggestion, currentMemberEmail)
-	if len(ownGetsBackSuggestions) == 0 && len(ownGiveSuggestions) == 0 {
-		planSuggestion.Brief = "Settled"
-		planSuggestion.Operation = OpSettled
-	} else {
-		getsSentence, getsBackAmount := formatSentence(ownGetsBackSuggestions, true)
-		givesSentence, givesAmount := formatSentence(ownGiveSuggestions, false)
-
-		// Populate brief
-		if getsBackAmount > 0 {
-			planSuggestion.Operation = OpGetsBack
-			planSuggestion.Brief = "You gets back " + float64Str(getsBackAmount) + " from " + getsSentence
-		}
-
-		if givesAmount > 0 {
-			if planSuggestion.Brief != "" {
-				planSuggestion.Brief = planSuggestion.Brief + " and "
-			}
-			planSuggestion.Brief = "You have to give " + float64Str(givesAmount) + " to " + givesSentence
-
-			if planSuggestion.Operation == OpGetsBack {
-				planSuggestion.Operation = OpBoth
-			} else {
-				planSuggestion.Operation = OpPaid
-			}
-
-		}
-	}
-}
-
 func splitSuggestionsByGettersAndGivers(planSuggestion *PlanSuggestion, currentMemberEmail string) ([]Suggestion, []Suggestion) {
 	ownGetsBackSuggestions := make([]Suggestion, 0)
 	ownGiveSuggestions := make([]Suggestion, 0)
@@ -282,30 +333,3 @@ func splitSuggestionsByGettersAndGivers(planSuggestion *PlanSuggestion, currentM
 	}
 	return ownGetsBackSuggestions, ownGiveSuggestions
 }
-
-func formatSentence(suggestions []Suggestion, findGivers bool) (string, float64) {
-	var sentence string
-	var totalAmount float64
-	lenOfSuggestions := len(suggestions)
-	for i := 0; i < lenOfSuggestions; i++ {
-		suggestion := suggestions[i]
-		theOtherMemberName := suggestion.AMembername
-		if findGivers {
-			theOtherMemberName = suggestion.BMembername
-		}
-		totalAmount = totalAmount + suggestion.Amount
-
-		if i == lenOfSuggestions-1 {
-			if sentence == "" {
-				sentence = theOtherMemberName + ". "
-			} else {
-				sentence = sentence + " and " + theOtherMemberName + ". "
-			}
-
-		} else {
-			sentence = sentence + theOtherMemberName + ", "
-		}
-
-	}
-	return sentence, totalAmount
-}
@@ -2,8 +2,13 @@ package splitter
 
 import (
 	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
 	"strconv"
 	"testing"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
 )
 
 func TestCreateTotalSuggestion(t *testing.T) {
@@ -13,7 +18,7 @@ func TestCreateTotalSuggestion(t *testing.T) {
 	fmt.Println("members ......", members)
 	fmt.Println("shares ......", shares)
 
-	suggestions := CreateTotalSuggestion(1, 4000, members, "vijay_0", shares)
+	suggestions := CreateTotalSuggestion(1, money.FromFloat(4000), members, "vijay_0", shares)
 	fmt.Println("suggestions ......", suggestions)
 }
 
@@ -39,10 +44,129 @@ func createDummyShares() []Share {
 			Tripid:      1,
 			Memberemail: "vijay_" + strconv.FormatInt(int64(i), 10),
 			Membername:  "vijay_" + strconv.FormatInt(int64(i), 10),
-			Share:       1000,
-			Paid:        100,
+			Share:       money.FromFloat(1000),
+			Paid:        money.FromFloat(100),
 		}
 		shares[i] = share
 	}
 	return shares
 }
+
+// recursiveGenerateSuggestions is the recursive matcher generateSuggestions replaced. It is kept to compare the
+// suggestions and the speed of the two.
+func recursiveGenerateSuggestions(posShares []*Share, negShares []*Share, planSuggestion *PlanSuggestion) {
+	sort.Slice(posShares, func(i, j int) bool { return posShares[i].Diff > posShares[j].Diff })
+	sort.Slice(negShares, func(i, j int) bool { return negShares[i].Diff < negShares[j].Diff })
+
+	for i := 0; i < len(posShares); i++ {
+		positiveShare := posShares[i]
+		if isTallyed(positiveShare.Diff) {
+			continue
+		}
+		for j := 0; j < len(negShares); j++ {
+			negativeShare := negShares[j]
+			if isTallyed(negativeShare.Diff) {
+				continue
+			}
+			if positiveShare.Diff >= -negativeShare.Diff {
+				planSuggestion.Suggestions = append(planSuggestion.Suggestions, createSuggestion(-negativeShare.Diff, positiveShare, negativeShare))
+				positiveShare.Diff = positiveShare.Diff + negativeShare.Diff
+				negativeShare.Diff = 0
+			} else {
+				planSuggestion.Suggestions = append(planSuggestion.Suggestions, createSuggestion(positiveShare.Diff, positiveShare, negativeShare))
+				negativeShare.Diff = negativeShare.Diff + positiveShare.Diff
+				positiveShare.Diff = 0
+			}
+			recursiveGenerateSuggestions(posShares, negShares, planSuggestion)
+			return
+		}
+	}
+}
+
+// distinctBalanceShares returns the positive and negative shares of count members with distinct balances adding up to zero
+func distinctBalanceShares(count int) ([]*Share, []*Share) {
+	random := rand.New(rand.NewSource(int64(count)))
+	balances := make([]money.Money, count)
+	var total money.Money
+	for i := 0; i < count-1; i++ {
+		balances[i] = money.FromMinor(random.Int63n(2000000)-1000000)*1000 + money.FromMinor(int64(i))
+		total = total + balances[i]
+	}
+	balances[count-1] = -total
+
+	_, shares := sharesForBalances(balances)
+	return posNegShares(0, shares)
+}
+
+func TestGenerateSuggestionsKeepsTheOrder(t *testing.T) {
+	for _, count := range []int{2, 5, 10, 50} {
+		posShares, negShares := distinctBalanceShares(count)
+		got := &PlanSuggestion{}
+		generateSuggestions(posShares, negShares, got)
+
+		posShares, negShares = distinctBalanceShares(count)
+		want := &PlanSuggestion{}
+		recursiveGenerateSuggestions(posShares, negShares, want)
+
+		if len(got.Suggestions) != len(want.Suggestions) {
+			t.Fatalf("%d members: %d suggestions, want %d", count, len(got.Suggestions), len(want.Suggestions))
+		}
+		for i := range want.Suggestions {
+			if !reflect.DeepEqual(got.Suggestions[i], want.Suggestions[i]) {
+				t.Errorf("%d members: suggestion %d = %+v, want %+v", count, i, got.Suggestions[i], want.Suggestions[i])
+			}
+		}
+	}
+}
+
+func benchmarkSuggestions(b *testing.B, count int, generate func([]*Share, []*Share, *PlanSuggestion)) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		posShares, negShares := distinctBalanceShares(count)
+		planSuggestion := &PlanSuggestion{}
+		b.StartTimer()
+		generate(posShares, negShares, planSuggestion)
+	}
+}
+
+func BenchmarkGenerateSuggestions10(b *testing.B)  { benchmarkSuggestions(b, 10, generateSuggestions) }
+func BenchmarkGenerateSuggestions100(b *testing.B) { benchmarkSuggestions(b, 100, generateSuggestions) }
+func BenchmarkGenerateSuggestions1000(b *testing.B) {
+	benchmarkSuggestions(b, 1000, generateSuggestions)
+}
+
+func BenchmarkRecursiveGenerateSuggestions10(b *testing.B) {
+	benchmarkSuggestions(b, 10, recursiveGenerateSuggestions)
+}
+func BenchmarkRecursiveGenerateSuggestions100(b *testing.B) {
+	benchmarkSuggestions(b, 100, recursiveGenerateSuggestions)
+}
+func BenchmarkRecursiveGenerateSuggestions1000(b *testing.B) {
+	benchmarkSuggestions(b, 1000, recursiveGenerateSuggestions)
+}
+
+func TestTransfersReduceTheSuggestions(t *testing.T) {
+	// vijay_0 paid 300 for everyone, vijay_1 paid back 60 of the 100 owed
+	members := createDummyMembers()
+	shares := make([]Share, 0)
+	for i, member := range members {
+		share := Share{Tripid: 1, Planid: 1, Memberemail: member.Email, Benefactoremail: member.Email, Share: money.FromFloat(100)}
+		if i == 0 {
+			share.Paid = money.FromFloat(300)
+		}
+		shares = append(shares, share)
+	}
+	shares = append(shares, Share{Tripid: 1, Memberemail: "vijay_1", Benefactoremail: "vijay_0", Paid: money.FromFloat(60)})
+
+	planSuggestion := CreateTotalSuggestion(1, money.FromFloat(300), members, "vijay_1", shares)
+	owes := make(map[string]money.Money)
+	for _, suggestion := range planSuggestion.Suggestions {
+		if suggestion.AMemberemail != "vijay_0" {
+			t.Errorf("unexpected suggestion %+v", suggestion)
+		}
+		owes[suggestion.BMemberemail] = suggestion.Amount
+	}
+	if owes["vijay_1"] != money.FromFloat(40) || owes["vijay_2"] != money.FromFloat(100) {
+		t.Errorf("unexpected suggestions %+v", planSuggestion.Suggestions)
+	}
+}
@@ -0,0 +1,96 @@
+package splitter
+
+import (
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+// attributeTrips splits the amount of each suggestion over the trips in which the member paying owes.
+// Trips in which the member getting back is owed are used first, so that a transfer is attributed to the
+// trips the two members share whenever the netting allows it.
+func attributeTrips(members []Member, shares []Share, suggestions []Suggestion) {
+	tripOrder := make([]int64, 0)
+	diffs := make(map[string]map[int64]money.Money)
+	for _, share := range createSharesOutOfBenefactor(0, members, shares) {
+		if diffs[share.Memberemail] == nil {
+			diffs[share.Memberemail] = make(map[int64]money.Money)
+		}
+		if !containsTrip(tripOrder, share.Tripid) {
+			tripOrder = append(tripOrder, share.Tripid)
+		}
+		diffs[share.Memberemail][share.Tripid] += share.Paid - share.Share
+	}
+
+	for i := range suggestions {
+		suggestion := &suggestions[i]
+		payer, getter := diffs[suggestion.BMemberemail], diffs[suggestion.AMemberemail]
+		if payer == nil || getter == nil {
+			continue
+		}
+		amounts := make(map[int64]money.Money)
+		remaining := suggestion.Amount
+
+		// trips where both of them have to settle
+		for _, tripId := range tripOrder {
+			if remaining == 0 {
+				break
+			}
+			if payer[tripId] < 0 && getter[tripId] > 0 {
+				take := minMoney(remaining, minMoney(-payer[tripId], getter[tripId]))
+				payer[tripId] += take
+				getter[tripId] -= take
+				amounts[tripId] += take
+				remaining -= take
+			}
+		}
+		// the rest is netted with the trips where the payer still owes
+		for _, tripId := range tripOrder {
+			if remaining == 0 {
+				break
+			}
+			if payer[tripId] < 0 {
+				take := minMoney(remaining, -payer[tripId])
+				payer[tripId] += take
+				amounts[tripId] += take
+				remaining -= take
+				settleCredit(getter, tripOrder, take)
+			}
+		}
+
+		suggestion.Trips = make([]TripAmount, 0)
+		for _, tripId := range tripOrder {
+			if amounts[tripId] != 0 {
+				suggestion.Trips = append(suggestion.Trips, TripAmount{Tripid: tripId, Amount: amounts[tripId]})
+			}
+		}
+	}
+}
+
+// settleCredit reduces the credit of the member getting back across the trips by the amount
+func settleCredit(diffs map[int64]money.Money, tripOrder []int64, amount money.Money) {
+	for _, tripId := range tripOrder {
+		if amount == 0 {
+			return
+		}
+		if diffs[tripId] > 0 {
+			take := minMoney(amount, diffs[tripId])
+			diffs[tripId] -= take
+			amount -= take
+		}
+	}
+}
+
+func containsTrip(tripIds []int64, tripId int64) bool {
+	for _, id := range tripIds {
+		if id == tripId {
+			return true
+		}
+	}
+	return false
+}
+
+func minMoney(a, b money.Money) money.Money {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,74 @@
+package splitter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+func tripShare(tripId, planId int64, email string, paid, share int64) Share {
+	return Share{Tripid: tripId, Planid: planId, Memberemail: email, Membername: email, Benefactoremail: email, Paid: money.FromMinor(paid), Share: money.FromMinor(share)}
+}
+
+func TestAllTripsNetsTheBalances(t *testing.T) {
+	members := []Member{{Email: "gus", Name: "gus"}, {Email: "walt", Name: "walt"}, {Email: "jesse", Name: "jesse"}}
+	shares := []Share{
+		// trip 1: gus paid 100 for gus and walt
+		tripShare(1, 1, "gus", 10000, 5000),
+		tripShare(1, 1, "walt", 0, 5000),
+		// trip 2: walt paid 40 for gus and walt
+		tripShare(2, 1, "walt", 4000, 2000),
+		tripShare(2, 1, "gus", 0, 2000),
+		// trip 3: gus paid 60 for gus and jesse, jesse paid back 10
+		tripShare(3, 1, "gus", 6000, 3000),
+		tripShare(3, 1, "jesse", 0, 3000),
+		{Tripid: 3, Memberemail: "jesse", Benefactoremail: "gus", Paid: money.FromMinor(1000)},
+	}
+
+	planSuggestion := CreateTotalSuggestion(0, money.FromMinor(20000), members, "gus", shares, WithAllTrips())
+	want := []Suggestion{
+		{AMemberemail: "gus", BMemberemail: "walt", Amount: 3000, Trips: []TripAmount{{Tripid: 1, Amount: 3000}}},
+		{AMemberemail: "gus", BMemberemail: "jesse", Amount: 2000, Trips: []TripAmount{{Tripid: 3, Amount: 2000}}},
+	}
+	if len(planSuggestion.Suggestions) != len(want) {
+		t.Fatalf("got %+v", planSuggestion.Suggestions)
+	}
+	for i, suggestion := range planSuggestion.Suggestions {
+		got := Suggestion{AMemberemail: suggestion.AMemberemail, BMemberemail: suggestion.BMemberemail, Amount: suggestion.Amount, Trips: suggestion.Trips}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("suggestion %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestAllTripsAttributesEveryAmount(t *testing.T) {
+	members := []Member{{Email: "a"}, {Email: "b"}, {Email: "c"}, {Email: "d"}}
+	shares := []Share{
+		tripShare(1, 1, "a", 9000, 3000),
+		tripShare(1, 1, "b", 0, 3000),
+		tripShare(1, 1, "c", 0, 3000),
+		tripShare(2, 1, "b", 8000, 2000),
+		tripShare(2, 1, "a", 0, 2000),
+		tripShare(2, 1, "c", 0, 2000),
+		tripShare(2, 1, "d", 0, 2000),
+		tripShare(3, 1, "d", 1000, 500),
+		tripShare(3, 1, "c", 0, 500),
+	}
+
+	for _, solver := range []Solver{SolverGreedy, SolverMinTransfers} {
+		planSuggestion := CreateTotalSuggestion(0, 0, members, "", shares, WithAllTrips(), WithSolver(solver))
+		for _, suggestion := range planSuggestion.Suggestions {
+			var total money.Money
+			for _, trip := range suggestion.Trips {
+				if trip.Amount <= 0 {
+					t.Errorf("%s: %+v has a trip without amount", solver, suggestion)
+				}
+				total = total + trip.Amount
+			}
+			if total != suggestion.Amount {
+				t.Errorf("%s: trips of %+v add up to %s", solver, suggestion, total)
+			}
+		}
+	}
+}
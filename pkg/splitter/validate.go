@@ -0,0 +1,207 @@
+package splitter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+const (
+	TotalPaid  = "paid"  // total amount paid by the members
+	TotalShare = "share" // total of the shares of the members
+)
+
+//JSONError is returned when the members or the shares could not be decoded
+type JSONError struct {
+	Field string // membersJson or sharesJson
+	Err   error
+}
+
+func (e *JSONError) Error() string {
+	return "Error while decoding " + e.Field + ": " + e.Err.Error()
+}
+
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+//UnknownMemberError is returned when a share belongs to a member who is not one of the members
+type UnknownMemberError struct {
+	Email string
+}
+
+func (e *UnknownMemberError) Error() string {
+	return fmt.Sprintf("%s is not a member", e.Email)
+}
+
+//NegativeAmountError is returned when the bill, a share or a payment is negative
+type NegativeAmountError struct {
+	Email  string // member of the share, empty for the bill
+	Field  string // Paid, Share or Bill
+	Amount money.Money
+}
+
+func (e *NegativeAmountError) Error() string {
+	if e.Email == "" {
+		return fmt.Sprintf("%s amount %s should not be negative", e.Field, e.Amount)
+	}
+	return fmt.Sprintf("%s of %s %s should not be negative", e.Field, e.Email, e.Amount)
+}
+
+//TotalsMismatchError is returned when the total paid or the total share is not the bill amount
+type TotalsMismatchError struct {
+	Total  string // TotalPaid or TotalShare
+	Amount money.Money
+	Bill   money.Money
+}
+
+func (e *TotalsMismatchError) Error() string {
+	more := e.Amount > e.Bill
+	switch {
+	case e.Total == TotalPaid && more:
+		return "Total amount paid more than the bill amount"
+	case e.Total == TotalPaid:
+		return "Total amount paid less than the bill amount"
+	case more:
+		return "Total share is more than the bill amount"
+	default:
+		return "Total share is less than the bill amount"
+	}
+}
+
+//ValidationResult lists every problem found in the shares. It is valid when there are no problems.
+type ValidationResult struct {
+	Problems []error
+}
+
+//Valid tells whether the shares are free of problems
+func (result *ValidationResult) Valid() bool {
+	return len(result.Problems) == 0
+}
+
+//Err returns the result as an error, nil when it is valid
+func (result *ValidationResult) Err() error {
+	if result.Valid() {
+		return nil
+	}
+	return result
+}
+
+func (result *ValidationResult) Error() string {
+	messages := make([]string, len(result.Problems))
+	for i, problem := range result.Problems {
+		messages[i] = problem.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+//As lets errors.As find the typed errors of the problems. Unwrap() []error would need Go 1.20.
+func (result *ValidationResult) As(target interface{}) bool {
+	for _, problem := range result.Problems {
+		if errors.As(problem, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (result *ValidationResult) add(err error) {
+	result.Problems = append(result.Problems, err)
+}
+
+//ValidateShares makes sure the total paid and the total share are the bill amount and that no amount is negative.
+func ValidateShares(sharesJson string, billAmount float64) *ValidationResult {
+	shares, err := decodeShares(sharesJson)
+	if err != nil {
+		return &ValidationResult{Problems: []error{err}}
+	}
+	return ValidateSharesForBill(money.FromFloat(billAmount), nil, shares)
+}
+
+//ValidateSharesForBill is ValidateShares for the decoded shares. The members of the shares are checked too if members are given.
+func ValidateSharesForBill(billAmount money.Money, members []Member, shares []Share) *ValidationResult {
+	result := validateAmounts(0, billAmount, members, shares)
+
+	var totalAmountPaid money.Money
+	var totalShare money.Money
+	for _, share := range shares {
+		if !isTransfer(share) {
+			totalAmountPaid = totalAmountPaid + share.Paid
+		}
+		totalShare = totalShare + share.Share
+	}
+	if totalAmountPaid != billAmount {
+		result.add(&TotalsMismatchError{Total: TotalPaid, Amount: totalAmountPaid, Bill: billAmount})
+	}
+	if totalShare != billAmount {
+		result.add(&TotalsMismatchError{Total: TotalShare, Amount: totalShare, Bill: billAmount})
+	}
+	return result
+}
+
+// validateAmounts checks the shares of the plan for unknown members and negative amounts
+func validateAmounts(planId int64, billAmount money.Money, members []Member, shares []Share) *ValidationResult {
+	result := &ValidationResult{}
+	if billAmount < 0 {
+		result.add(&NegativeAmountError{Field: "Bill", Amount: billAmount})
+	}
+
+	known := make(map[string]bool)
+	for _, member := range members {
+		known[member.Email] = true
+	}
+	reported := make(map[string]bool)
+	for _, share := range shares {
+		if !isCurrentPlan(share.Planid, planId) {
+			continue
+		}
+		if members != nil {
+			for _, email := range []string{share.Memberemail, share.Benefactoremail} {
+				if email != "" && !known[email] && !reported[email] {
+					reported[email] = true
+					result.add(&UnknownMemberError{Email: email})
+				}
+			}
+		}
+		if share.Paid < 0 {
+			result.add(&NegativeAmountError{Email: share.Memberemail, Field: "Paid", Amount: share.Paid})
+		}
+		if share.Share < 0 {
+			result.add(&NegativeAmountError{Email: share.Memberemail, Field: "Share", Amount: share.Share})
+		}
+	}
+	return result
+}
+
+// isTransfer tells whether the share is an amount paid by the member to the benefactor instead of the bill
+func isTransfer(share Share) bool {
+	return share.Benefactoremail != "" && share.Benefactoremail != share.Memberemail
+}
+
+func decodeMembers(membersJson string) ([]Member, error) {
+	members, err := parseMembers(membersJson)
+	if err != nil {
+		return nil, &JSONError{Field: "membersJson", Err: err}
+	}
+	return members, nil
+}
+
+func decodeShares(sharesJson string) ([]Share, error) {
+	shares, err := parseShares(sharesJson)
+	if err != nil {
+		return nil, &JSONError{Field: "sharesJson", Err: err}
+	}
+	return shares, nil
+}
+
+// marshalShares writes the shares back as JSON for the wrappers
+func marshalShares(shares []Share) (string, error) {
+	sharesJson, err := json.Marshal(shares)
+	if err != nil {
+		return "", err
+	}
+	return string(sharesJson), nil
+}
@@ -0,0 +1,91 @@
+package splitter
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/pkg/money"
+)
+
+func TestValidateSharesListsEveryProblem(t *testing.T) {
+	shares := []Share{
+		{Memberemail: "gus", Benefactoremail: "gus", Paid: money.FromMinor(5000), Share: money.FromMinor(-100)},
+		{Memberemail: "walt", Benefactoremail: "walt", Paid: money.FromMinor(-200), Share: money.FromMinor(3000)},
+	}
+	sharesJSON, _ := json.Marshal(shares)
+
+	result := ValidateShares(string(sharesJSON), 50)
+	if result.Valid() {
+		t.Fatal("expected problems")
+	}
+	if len(result.Problems) != 4 {
+		t.Fatalf("expected 4 problems, got %v", result.Problems)
+	}
+
+	var negative *NegativeAmountError
+	if !errors.As(result.Problems[0], &negative) || negative.Email != "gus" || negative.Field != "Share" {
+		t.Errorf("unexpected first problem %v", result.Problems[0])
+	}
+	var mismatch *TotalsMismatchError
+	if !errors.As(result.Problems[2], &mismatch) || mismatch.Total != TotalPaid || mismatch.Amount != 4800 {
+		t.Errorf("unexpected third problem %v", result.Problems[2])
+	}
+	if !errors.As(result.Err(), &mismatch) {
+		t.Errorf("expected errors.As to find the problems of the result")
+	}
+
+	if result := ValidateShares("{", 50); result.Valid() {
+		t.Error("expected invalid JSON to be a problem")
+	} else {
+		var jsonErr *JSONError
+		if !errors.As(result.Problems[0], &jsonErr) || jsonErr.Field != "sharesJson" {
+			t.Errorf("unexpected problem %v", result.Problems[0])
+		}
+	}
+
+	valid := []Share{
+		{Memberemail: "gus", Benefactoremail: "gus", Paid: money.FromMinor(5000), Share: money.FromMinor(2500)},
+		{Memberemail: "walt", Benefactoremail: "walt", Share: money.FromMinor(2500)},
+		{Memberemail: "walt", Benefactoremail: "gus", Paid: money.FromMinor(2500)}, // walt paid gus back
+	}
+	validJSON, _ := json.Marshal(valid)
+	if result := ValidateShares(string(validJSON), 50); !result.Valid() || result.Err() != nil {
+		t.Errorf("unexpected problems %v", result.Problems)
+	}
+}
+
+func TestWrapperVariantsReturnErrors(t *testing.T) {
+	membersJSON, _ := json.Marshal(createDummyMembers())
+
+	if _, _, _, err := SplitSharesForBillJSON(1, 1, 100, "vijay_0", "[", "[]"); err == nil {
+		t.Error("expected an error for invalid members")
+	}
+
+	unknown, _ := json.Marshal([]Share{{Planid: 1, Memberemail: "saul", Benefactoremail: "saul", Share: money.FromMinor(100)}})
+	_, _, _, err := SplitSharesForBillJSON(1, 1, 100, "vijay_0", string(membersJSON), string(unknown))
+	var unknownErr *UnknownMemberError
+	if !errors.As(err, &unknownErr) || unknownErr.Email != "saul" {
+		t.Errorf("expected unknown member saul, got %v", err)
+	}
+
+	sharesJSON, _, _, err := SplitSharesForBillJSON(1, 1, 90, "vijay_0", string(membersJSON), "[]")
+	if err != nil || sharesJSON == "" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if _, err := CreateTotalSuggestionJSON(1, 90, string(membersJSON), "vijay_0", sharesJSON); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	var mismatch *TotalsMismatchError
+	if _, err := CreateTotalSuggestionJSON(1, 100, string(membersJSON), "vijay_0", sharesJSON); !errors.As(err, &mismatch) {
+		t.Errorf("expected totals mismatch, got %v", err)
+	}
+	if _, err := CreateIndividualSuggestionJSON(1, 1, 90, "", time.Time{}, string(membersJSON), "vijay_0", sharesJSON); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := CreateIndividualSuggestionJSON(1, 1, 90, "", time.Time{}, string(membersJSON), "vijay_0", "nope"); err == nil {
+		t.Error("expected an error for invalid shares")
+	}
+}
@@ -0,0 +1,276 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+)
+
+// The bodies are the stored types as they go over the wire. Amounts are written with the minor digits of the
+// currency the body carries eg. 1250 for ¥1250 and 1.250 for 1.250 KWD, unlike the JSON of money.Money which
+// always has two minor digits.
+
+// shareBody is a database.Share with the amounts in the currency of its transaction
+type shareBody struct {
+	Id     int64
+	Member string
+	Amount json.Number
+	Paid   json.Number
+	Auto   bool
+	Weight int64
+}
+
+// itemBody is a database.Item with the price in the currency of its transaction
+type itemBody struct {
+	Name     string
+	Price    json.Number
+	Quantity int64
+	Members  []string
+}
+
+// transactionBody is a database.Transaction. Currency is the currency of the trip when not given.
+type transactionBody struct {
+	Id       int64
+	Name     string
+	Amount   json.Number
+	Currency string
+	Date     time.Time
+	Split    string
+	Shares   []shareBody
+	Items    []itemBody  `json:",omitempty"`
+	Tax      json.Number `json:",omitempty"`
+	Service  json.Number `json:",omitempty"`
+	Tip      json.Number `json:",omitempty"`
+}
+
+// settlementBody is a database.Settlement. Currency is the currency of the trip when not given.
+type settlementBody struct {
+	Id        int64
+	From      string
+	To        string
+	Amount    json.Number
+	Currency  string
+	Date      time.Time
+	Note      string
+	Reference string
+}
+
+// balanceBody is a splitter.Balance in the currency of the balances
+type balanceBody struct {
+	Memberemail  string
+	Membername   string
+	Paid         json.Number
+	Share        json.Number
+	Settled      json.Number
+	Net          json.Number
+	Transactions int
+}
+
+// balancesBody is the balance of each member along with the currency of the amounts
+type balancesBody struct {
+	Currency string
+	Balances []balanceBody
+}
+
+// suggestionBody is a splitter.Suggestion in the currency of its plan
+type suggestionBody struct {
+	AMemberemail string
+	AMembername  string
+	BMemberemail string
+	BMembername  string
+	Amount       json.Number
+}
+
+// briefAmountsBody is a splitter.BriefAmounts in the currency of its plan
+type briefAmountsBody struct {
+	Language string
+	GetsBack json.Number
+	From     []string
+	Gives    json.Number
+	To       []string
+}
+
+// planBody is a splitter.PlanSuggestion
+type planBody struct {
+	Tripid      int64
+	Currency    string
+	Brief       string
+	Amounts     briefAmountsBody
+	Operation   int
+	Suggestions []suggestionBody
+}
+
+func formatAmount(amount money.Money, currency string) json.Number {
+	return json.Number(amount.Format(currency))
+}
+
+// parseAmount reads the amount in the currency, refusing more decimals than the currency has
+func parseAmount(number json.Number, field string, currency string) (money.Money, error) {
+	if number == "" {
+		return 0, nil
+	}
+	amount, err := money.Parse(number.String(), currency)
+	if err != nil {
+		return 0, badRequest(fmt.Sprintf("%s %s is not a valid amount in %s: %s", field, number, currencyName(currency), err.Error()))
+	}
+	return amount, nil
+}
+
+func currencyName(currency string) string {
+	if currency == "" {
+		return "the currency of the trip"
+	}
+	return currency
+}
+
+// amountCurrency is the currency the amounts of the body are in, the currency of the trip if the body has none
+func amountCurrency(currency string, tripCurrency string) string {
+	if strings.TrimSpace(currency) == "" {
+		return strings.ToUpper(tripCurrency)
+	}
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+func newTransactionBody(transaction database.Transaction, tripCurrency string) transactionBody {
+	currency := amountCurrency(transaction.Currency, tripCurrency)
+	body := transactionBody{
+		Id:       transaction.Id,
+		Name:     transaction.Name,
+		Amount:   formatAmount(transaction.Amount, currency),
+		Currency: currency,
+		Date:     transaction.Date,
+		Split:    transaction.Split,
+		Shares:   make([]shareBody, len(transaction.Shares)),
+	}
+	for i, share := range transaction.Shares {
+		body.Shares[i] = shareBody{
+			Id:     share.Id,
+			Member: share.Member,
+			Amount: formatAmount(share.Amount, currency),
+			Paid:   formatAmount(share.Paid, currency),
+			Auto:   share.Auto,
+			Weight: share.Weight,
+		}
+	}
+	if len(transaction.Items) > 0 {
+		for _, item := range transaction.Items {
+			body.Items = append(body.Items, itemBody{Name: item.Name, Price: formatAmount(item.Price, currency), Quantity: item.Quantity, Members: item.Members})
+		}
+		body.Tax = formatAmount(transaction.Tax, currency)
+		body.Service = formatAmount(transaction.Service, currency)
+		body.Tip = formatAmount(transaction.Tip, currency)
+	}
+	return body
+}
+
+// transaction reads the amounts of the body in its currency
+func (body transactionBody) transaction(tripCurrency string) (database.Transaction, error) {
+	currency := amountCurrency(body.Currency, tripCurrency)
+	transaction := database.Transaction{
+		Id:       body.Id,
+		Name:     body.Name,
+		Currency: body.Currency,
+		Date:     body.Date,
+		Split:    body.Split,
+		Shares:   make([]database.Share, len(body.Shares)),
+	}
+	var err error
+	if transaction.Amount, err = parseAmount(body.Amount, "Amount", currency); err != nil {
+		return transaction, err
+	}
+	for i, share := range body.Shares {
+		transaction.Shares[i] = database.Share{Id: share.Id, Member: share.Member, Auto: share.Auto, Weight: share.Weight}
+		if transaction.Shares[i].Amount, err = parseAmount(share.Amount, "Amount of "+share.Member, currency); err != nil {
+			return transaction, err
+		}
+		if transaction.Shares[i].Paid, err = parseAmount(share.Paid, "Paid of "+share.Member, currency); err != nil {
+			return transaction, err
+		}
+	}
+	for _, item := range body.Items {
+		price, err := parseAmount(item.Price, "Price of "+item.Name, currency)
+		if err != nil {
+			return transaction, err
+		}
+		transaction.Items = append(transaction.Items, database.Item{Name: item.Name, Price: price, Quantity: item.Quantity, Members: item.Members})
+	}
+	return transaction, nil
+}
+
+func newSettlementBody(settlement database.Settlement, tripCurrency string) settlementBody {
+	currency := amountCurrency(settlement.Currency, tripCurrency)
+	return settlementBody{
+		Id:        settlement.Id,
+		From:      settlement.From,
+		To:        settlement.To,
+		Amount:    formatAmount(settlement.Amount, currency),
+		Currency:  currency,
+		Date:      settlement.Date,
+		Note:      settlement.Note,
+		Reference: settlement.Reference,
+	}
+}
+
+func (body settlementBody) settlement(tripCurrency string) (database.Settlement, error) {
+	settlement := database.Settlement{
+		Id:        body.Id,
+		From:      body.From,
+		To:        body.To,
+		Currency:  body.Currency,
+		Date:      body.Date,
+		Note:      body.Note,
+		Reference: body.Reference,
+	}
+	var err error
+	settlement.Amount, err = parseAmount(body.Amount, "Amount", amountCurrency(body.Currency, tripCurrency))
+	return settlement, err
+}
+
+func newBalancesBody(balances []splitter.Balance, currency string) balancesBody {
+	body := balancesBody{Currency: currency, Balances: make([]balanceBody, len(balances))}
+	for i, balance := range balances {
+		body.Balances[i] = balanceBody{
+			Memberemail:  balance.Memberemail,
+			Membername:   balance.Membername,
+			Paid:         formatAmount(balance.Paid, currency),
+			Share:        formatAmount(balance.Share, currency),
+			Settled:      formatAmount(balance.Settled, currency),
+			Net:          formatAmount(balance.Net, currency),
+			Transactions: balance.Transactions,
+		}
+	}
+	return body
+}
+
+func newPlanBody(planSuggestion *splitter.PlanSuggestion, currency string) planBody {
+	amounts := planSuggestion.Amounts
+	body := planBody{
+		Tripid:   planSuggestion.Tripid,
+		Currency: currency,
+		Brief:    planSuggestion.Brief,
+		Amounts: briefAmountsBody{
+			Language: amounts.Language,
+			GetsBack: formatAmount(amounts.GetsBack, currency),
+			From:     amounts.From,
+			Gives:    formatAmount(amounts.Gives, currency),
+			To:       amounts.To,
+		},
+		Operation:   planSuggestion.Operation,
+		Suggestions: make([]suggestionBody, len(planSuggestion.Suggestions)),
+	}
+	for i, suggestion := range planSuggestion.Suggestions {
+		body.Suggestions[i] = suggestionBody{
+			AMemberemail: suggestion.AMemberemail,
+			AMembername:  suggestion.AMembername,
+			BMemberemail: suggestion.BMemberemail,
+			BMembername:  suggestion.BMembername,
+			Amount:       formatAmount(suggestion.Amount, currency),
+		}
+	}
+	return body
+}
@@ -0,0 +1,673 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+)
+
+// tripBody is the trip as created through the API
+type tripBody struct {
+	Name      string
+	Members   []string
+	Currency  string
+	Remainder string // name of the remainder policy, see splitter.ParseRemainderPolicy
+}
+
+// tripPatch renames or archives the trip, the fields not given are left as they are
+type tripPatch struct {
+	Name     *string
+	Archived *bool
+}
+
+func listTrips(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	trips, err := database.Trips()
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, trips)
+}
+
+func createTrip(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	body := tripBody{}
+	if err := decode(w, r, &body); err != nil {
+		return err
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if _, err := splitter.ParseRemainderPolicy(body.Remainder); err != nil {
+		return badRequest(err.Error())
+	}
+	if err := database.CreateTrip(body.Name, body.Members, body.Currency, body.Remainder); err != nil {
+		return err
+	}
+
+	info, err := database.GetTrip(body.Name)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", "/trips/"+strconv.FormatInt(info.Id, 10))
+	return writeJSON(w, http.StatusCreated, info)
+}
+
+func getTrip(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	info, err := database.GetTrip(trip)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, info)
+}
+
+func updateTrip(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	patch := tripPatch{}
+	if err := decode(w, r, &patch); err != nil {
+		return err
+	}
+	info, err := database.GetTrip(trip)
+	if err != nil {
+		return err
+	}
+	if patch.Archived != nil && !*patch.Archived && info.Archived {
+		return &Error{Status: http.StatusConflict, Message: "Archived trip can't be reopened"}
+	}
+
+	if patch.Name != nil && strings.TrimSpace(*patch.Name) != trip {
+		if err := database.RenameTrip(trip, strings.TrimSpace(*patch.Name)); err != nil {
+			return err
+		}
+		trip = strings.TrimSpace(*patch.Name)
+	}
+	if patch.Archived != nil && *patch.Archived && !info.Archived {
+		if err := database.ArchiveTrip(trip); err != nil {
+			return err
+		}
+	}
+	return getTrip(w, r, trip, key)
+}
+
+func deleteTrip(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	if err := database.DeleteTrip(trip); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func listMembers(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	members, err := database.Members(trip)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, members)
+}
+
+func addMember(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	member := database.Member{}
+	if err := decode(w, r, &member); err != nil {
+		return err
+	}
+	if err := database.AddMember(trip, member); err != nil {
+		return err
+	}
+
+	added, err := findMember(trip, member.Name)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", r.URL.Path+"/"+url.PathEscape(added.Name))
+	return writeJSON(w, http.StatusCreated, added)
+}
+
+func getMember(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	member, err := findMember(trip, key)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, member)
+}
+
+func editMember(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	member := database.Member{}
+	if err := decode(w, r, &member); err != nil {
+		return err
+	}
+	old, err := findMember(trip, key)
+	if err != nil {
+		return err
+	}
+	if err := database.EditMember(trip, old.Name, member); err != nil {
+		return err
+	}
+	return getMember(w, r, trip, member.Name)
+}
+
+func removeMember(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	member, err := findMember(trip, key)
+	if err != nil {
+		return err
+	}
+	if err := database.RemoveMember(trip, member.Name); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// findMember finds the member of the trip by its name or one of its aliases
+func findMember(trip string, name string) (database.Member, error) {
+	members, err := database.Members(trip)
+	if err != nil {
+		return database.Member{}, err
+	}
+	member, ok := database.FindMember(members, name)
+	if !ok {
+		return database.Member{}, database.ErrMemberNotFound
+	}
+	return member, nil
+}
+
+func listTransactions(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	loaded, err := database.LoadTrip(trip)
+	if err != nil {
+		return err
+	}
+	bodies := make([]transactionBody, len(loaded.Transactions))
+	for i, transaction := range loaded.Transactions {
+		bodies[i] = newTransactionBody(transaction, loaded.Currency)
+	}
+	return writeJSON(w, http.StatusOK, bodies)
+}
+
+func addTransaction(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	transaction, err := decodeTransaction(w, r, trip)
+	if err != nil {
+		return err
+	}
+	if transaction.Date.IsZero() {
+		transaction.Date = time.Now()
+	}
+	if err := prepareTransaction(trip, -1, &transaction); err != nil {
+		return err
+	}
+	id, err := database.NewTrip(trip, transaction)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", r.URL.Path+"/"+strconv.FormatInt(id, 10))
+	return writeTransaction(w, http.StatusCreated, trip, id)
+}
+
+func getTransaction(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	id, err := parseID(key)
+	if err != nil {
+		return err
+	}
+	return writeTransaction(w, http.StatusOK, trip, id)
+}
+
+// decodeTransaction reads the transaction from the body, its amounts are in its currency
+func decodeTransaction(w http.ResponseWriter, r *http.Request, trip string) (database.Transaction, error) {
+	body := transactionBody{}
+	if err := decode(w, r, &body); err != nil {
+		return database.Transaction{}, err
+	}
+	info, err := database.GetTrip(trip)
+	if err != nil {
+		return database.Transaction{}, err
+	}
+	return body.transaction(info.Currency)
+}
+
+func writeTransaction(w http.ResponseWriter, status int, trip string, id int64) error {
+	info, err := database.GetTrip(trip)
+	if err != nil {
+		return err
+	}
+	transaction, err := database.FindTransaction(trip, id)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, status, newTransactionBody(*transaction, info.Currency))
+}
+
+func updateTransaction(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	id, err := parseID(key)
+	if err != nil {
+		return err
+	}
+	old, err := database.FindTransaction(trip, id)
+	if err != nil {
+		return err
+	}
+	transaction, err := decodeTransaction(w, r, trip)
+	if err != nil {
+		return err
+	}
+	if transaction.Date.IsZero() {
+		transaction.Date = old.Date
+	}
+	if err := prepareTransaction(trip, id, &transaction); err != nil {
+		return err
+	}
+	if err := database.UpdateTransaction(trip, id, transaction); err != nil {
+		return err
+	}
+	return writeTransaction(w, http.StatusOK, trip, id)
+}
+
+func deleteTransaction(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	id, err := parseID(key)
+	if err != nil {
+		return err
+	}
+	if err := database.DeleteTransaction(trip, id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// prepareTransaction resolves the members of the shares, splits the amount equally when no share amount is given
+// and validates the shares. The transaction is split equally by the server when its Split is equal or every
+// share amount is zero, otherwise the share amounts are taken as they are. Id is -1 for a new transaction.
+func prepareTransaction(trip string, id int64, transaction *database.Transaction) error {
+	transaction.Name = strings.TrimSpace(transaction.Name)
+	if transaction.Name == "" {
+		return badRequest("Please give the transaction name")
+	}
+	if len(transaction.Items) > 0 {
+		return badRequest("Itemised bills can only be added with the transaction command")
+	}
+	if len(transaction.Shares) == 0 {
+		return badRequest("Please give atleast one share")
+	}
+	if err := prepareCurrency(trip, &transaction.Currency, transaction.Date); err != nil {
+		return err
+	}
+
+	problems, err := resolveMembers(trip, transaction.Date, func(resolve func(name string) string) {
+		for i := range transaction.Shares {
+			transaction.Shares[i].Member = resolve(transaction.Shares[i].Member)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	switch transaction.Split {
+	case "equal":
+	case "", "exact":
+		transaction.Split = "exact"
+		if transaction.ShareTotal() == 0 {
+			transaction.Split = "equal"
+		}
+	default:
+		return badRequest("Split should be either equal or exact, the other split modes are only available with the transaction command")
+	}
+	if transaction.Split == "equal" {
+		if transaction.Amount <= 0 {
+			return badRequest("Please give the amount to split equally")
+		}
+	}
+	for i := range transaction.Shares {
+		transaction.Shares[i].Auto = transaction.Split == "equal"
+		transaction.Shares[i].Weight = 0
+	}
+	if transaction.Split == "equal" {
+		remainder, err := tripRemainder(trip, id)
+		if err != nil {
+			return err
+		}
+		if err := splitEqually(transaction.Shares, transaction.Amount, remainder); err != nil {
+			return err
+		}
+	}
+	if transaction.Amount == 0 {
+		transaction.Amount = transaction.ShareTotal()
+	}
+
+	shares := make([]splitter.Share, len(transaction.Shares))
+	for i, share := range transaction.Shares {
+		shares[i] = splitter.Share{
+			Memberemail:     share.Member,
+			Membername:      share.Member,
+			Benefactoremail: share.Member,
+			Share:           share.Amount,
+			Paid:            share.Paid,
+		}
+	}
+	result := splitter.ValidateSharesForBill(transaction.Amount, nil, shares)
+	result.Problems = append(problems, result.Problems...)
+	return result.Err()
+}
+
+// tripRemainder returns the remainder policy of the trip the way the transaction command does.
+// The position of the transaction in the trip is used by round robin; id -1 is a new transaction.
+func tripRemainder(trip string, id int64) (splitter.Remainder, error) {
+	remainder := splitter.Remainder{}
+	info, err := database.GetTrip(trip)
+	if err != nil {
+		return remainder, err
+	}
+	if remainder.Policy, err = splitter.ParseRemainderPolicy(info.Remainder); err != nil {
+		return remainder, err
+	}
+
+	if remainder.Policy == splitter.RemainderRoundRobin || remainder.Policy == splitter.RemainderRandom {
+		loaded, err := database.LoadTrip(trip)
+		if err != nil {
+			return remainder, err
+		}
+		remainder.Offset = int64(len(loaded.Transactions))
+		for i, transaction := range loaded.Transactions {
+			if transaction.Id == id {
+				remainder.Offset = int64(i)
+			}
+		}
+	}
+	remainder.Seed = remainder.Offset
+	return remainder, nil
+}
+
+// splitEqually splits the amount among the shares through the splitter, so the minor units left go where the remainder policy says
+func splitEqually(shares []database.Share, billAmount money.Money, remainder splitter.Remainder) error {
+	members := make([]splitter.Member, len(shares))
+	splitterShares := make([]splitter.Share, len(shares))
+	for i, share := range shares {
+		members[i] = splitter.Member{Name: share.Member, Email: share.Member}
+		splitterShares[i] = splitter.Share{
+			Memberemail:     share.Member,
+			Membername:      share.Member,
+			Benefactoremail: share.Member,
+			Paid:            share.Paid,
+			Auto:            true,
+		}
+	}
+
+	splitShares, _, ok := splitter.SplitSharesForBill(0, 0, billAmount, "", members, splitterShares, splitter.WithRemainder(remainder))
+	if !ok {
+		return errors.New("Could not split the amount equally among the shares")
+	}
+	for _, splitShare := range splitShares {
+		for i := range shares {
+			if shares[i].Member == splitShare.Memberemail {
+				shares[i].Amount = splitShare.Share
+			}
+		}
+	}
+	return nil
+}
+
+// prepareCurrency keeps the currency of an amount only when it is not the currency of the trip,
+// making sure an exchange rate to the currency of the trip is known on the date
+func prepareCurrency(trip string, currency *string, date time.Time) error {
+	info, err := database.GetTrip(trip)
+	if err != nil {
+		return err
+	}
+	*currency = strings.ToUpper(strings.TrimSpace(*currency))
+	if strings.EqualFold(*currency, info.Currency) {
+		*currency = ""
+	}
+	if *currency == "" {
+		return nil
+	}
+	if info.Currency == "" {
+		return badRequest(fmt.Sprintf("Trip has no currency to convert %s to", *currency))
+	}
+
+	rates, err := database.LoadRates()
+	if err != nil {
+		return err
+	}
+	_, err = rates.Lookup(*currency, info.Currency, date)
+	return err
+}
+
+// resolveMembers lets the fill function replace the names given by the names of the members of the trip.
+// Names which are not members of the trip or members who are not in the trip on the date are returned as problems.
+func resolveMembers(trip string, date time.Time, fill func(resolve func(name string) string)) ([]error, error) {
+	members, err := database.Members(trip)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]error, 0)
+	reported := make(map[string]bool)
+	fill(func(name string) string {
+		name = database.NormaliseName(name)
+		if name == "" {
+			return name
+		}
+		member, ok := database.FindMember(members, name)
+		switch {
+		case !ok && !reported[name]:
+			reported[name] = true
+			problems = append(problems, &splitter.UnknownMemberError{Email: name})
+		case ok && !member.Active(date) && !reported[member.Name]:
+			reported[member.Name] = true
+			problems = append(problems, fmt.Errorf("%s is not in the trip on %s", member.Name, date.Format("2006-01-02")))
+		}
+		if ok {
+			return member.Name
+		}
+		return name
+	})
+	return problems, nil
+}
+
+func listSettlements(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	info, err := database.GetTrip(trip)
+	if err != nil {
+		return err
+	}
+	settlements, err := database.Settlements(trip)
+	if err != nil {
+		return err
+	}
+	bodies := make([]settlementBody, len(settlements))
+	for i, settlement := range settlements {
+		bodies[i] = newSettlementBody(settlement, info.Currency)
+	}
+	return writeJSON(w, http.StatusOK, bodies)
+}
+
+func addSettlement(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	settlement, err := decodeSettlement(w, r, trip)
+	if err != nil {
+		return err
+	}
+	if settlement.Date.IsZero() {
+		settlement.Date = time.Now()
+	}
+	if err := prepareSettlement(trip, &settlement); err != nil {
+		return err
+	}
+	id, err := database.AddSettlement(trip, settlement)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", r.URL.Path+"/"+strconv.FormatInt(id, 10))
+	return writeSettlement(w, http.StatusCreated, trip, id)
+}
+
+func getSettlement(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	id, err := parseID(key)
+	if err != nil {
+		return err
+	}
+	return writeSettlement(w, http.StatusOK, trip, id)
+}
+
+// decodeSettlement reads the settlement from the body, its amount is in its currency
+func decodeSettlement(w http.ResponseWriter, r *http.Request, trip string) (database.Settlement, error) {
+	body := settlementBody{}
+	if err := decode(w, r, &body); err != nil {
+		return database.Settlement{}, err
+	}
+	info, err := database.GetTrip(trip)
+	if err != nil {
+		return database.Settlement{}, err
+	}
+	return body.settlement(info.Currency)
+}
+
+func writeSettlement(w http.ResponseWriter, status int, trip string, id int64) error {
+	info, err := database.GetTrip(trip)
+	if err != nil {
+		return err
+	}
+	settlement, err := findSettlement(trip, id)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, status, newSettlementBody(settlement, info.Currency))
+}
+
+func updateSettlement(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	id, err := parseID(key)
+	if err != nil {
+		return err
+	}
+	old, err := findSettlement(trip, id)
+	if err != nil {
+		return err
+	}
+	settlement, err := decodeSettlement(w, r, trip)
+	if err != nil {
+		return err
+	}
+	if settlement.Date.IsZero() {
+		settlement.Date = old.Date
+	}
+	if err := prepareSettlement(trip, &settlement); err != nil {
+		return err
+	}
+	if err := database.UpdateSettlement(trip, id, settlement); err != nil {
+		return err
+	}
+	return writeSettlement(w, http.StatusOK, trip, id)
+}
+
+func deleteSettlement(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	id, err := parseID(key)
+	if err != nil {
+		return err
+	}
+	if err := database.DeleteSettlement(trip, id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// prepareSettlement resolves the members who paid and got paid
+func prepareSettlement(trip string, settlement *database.Settlement) error {
+	if err := prepareCurrency(trip, &settlement.Currency, settlement.Date); err != nil {
+		return err
+	}
+	problems, err := resolveMembers(trip, settlement.Date, func(resolve func(name string) string) {
+		settlement.From = resolve(settlement.From)
+		settlement.To = resolve(settlement.To)
+	})
+	if err != nil {
+		return err
+	}
+	if err := (&splitter.ValidationResult{Problems: problems}).Err(); err != nil {
+		return err
+	}
+	if settlement.From == "" || settlement.To == "" || settlement.From == settlement.To || settlement.Amount <= 0 {
+		return database.ErrInvalidSettlement
+	}
+	return nil
+}
+
+func findSettlement(trip string, id int64) (database.Settlement, error) {
+	settlements, err := database.Settlements(trip)
+	if err != nil {
+		return database.Settlement{}, err
+	}
+	for _, settlement := range settlements {
+		if settlement.Id == id {
+			return settlement, nil
+		}
+	}
+	return database.Settlement{}, database.ErrSettlementNotFound
+}
+
+// suggestions answers who pays whom to settle the trip. The query takes the member the brief is written for,
+// the currency and the language of the brief and the solver eg. ?member=Ana&currency=EUR&lang=es&solver=min-transfers
+func suggestions(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	query := r.URL.Query()
+	loaded, members, shares, currency, err := tripShares(trip, query.Get("currency"))
+	if err != nil {
+		return err
+	}
+
+	opts := []splitter.Option{splitter.WithCurrency(currency)}
+	if query.Get("solver") != "" {
+		solver, err := splitter.ParseSolver(query.Get("solver"))
+		if err != nil {
+			return badRequest(err.Error())
+		}
+		opts = append(opts, splitter.WithSolver(solver))
+	}
+	if query.Get("lang") != "" {
+		opts = append(opts, splitter.WithLanguage(query.Get("lang")))
+	}
+	member := ""
+	if query.Get("member") != "" {
+		found, err := findMember(trip, query.Get("member"))
+		if err != nil {
+			return err
+		}
+		member = found.Name
+	}
+	return writeJSON(w, http.StatusOK, newPlanBody(splitter.CreateTotalSuggestion(loaded.Id, 0, members, member, shares, opts...), currency))
+}
+
+// balances answers what each member paid, shared and owes, in the currency given with ?currency=
+func balances(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	loaded, members, shares, currency, err := tripShares(trip, r.URL.Query().Get("currency"))
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, newBalancesBody(splitter.CreateBalances(loaded.Id, members, shares), currency))
+}
+
+// tripShares loads the trip with its members and its shares converted to the currency, the currency of the trip if not given.
+// Members read the briefs in their own language unless another is asked for.
+func tripShares(trip string, currency string) (*database.Trip, []splitter.Member, []splitter.Share, string, error) {
+	loaded, err := database.LoadTrip(trip)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = strings.ToUpper(loaded.Currency)
+	}
+	shares, err := loaded.SharesIn(currency)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+
+	registered, err := database.Members(trip)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+	members := loaded.Members()
+	for i := range members {
+		if member, ok := database.FindMember(registered, members[i].Email); ok {
+			members[i].Language = member.Language
+		}
+	}
+	return loaded, members, shares, currency, nil
+}
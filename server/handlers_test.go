@@ -0,0 +1,386 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+)
+
+// testTrip creates the trip goa in EUR with walt, jesse and gus
+func testTrip(t *testing.T) *Server {
+	t.Helper()
+	server := New("")
+	call(t, server, http.MethodPost, "/trips", `{"Name":"goa","Currency":"eur"}`, http.StatusCreated)
+	for _, member := range []string{`{"Name":"walt","Aliases":["heisenberg"]}`, `{"Name":"jesse","Language":"es"}`, `{"Name":"gus"}`} {
+		call(t, server, http.MethodPost, "/trips/goa/members", member, http.StatusCreated)
+	}
+	return server
+}
+
+func TestTrips(t *testing.T) {
+	defer inTempDir(t)()
+	server := New("")
+
+	w := call(t, server, http.MethodPost, "/trips", `{"Name":" goa ","Members":["walt"],"Currency":"eur"}`, http.StatusCreated)
+	info := database.TripInfo{}
+	decodeBody(t, w, &info)
+	if info.Name != "goa" || info.Currency != "EUR" || info.Id == 0 || w.Header().Get("Location") != "/trips/1" {
+		t.Errorf("got %+v at %s", info, w.Header().Get("Location"))
+	}
+
+	cases := []struct {
+		body   string
+		status int
+	}{
+		{`{"Name":"goa"}`, http.StatusConflict},
+		{`{"Name":"_goa"}`, http.StatusBadRequest},
+		{`{"Name":"lisbon","Remainder":"everyone"}`, http.StatusBadRequest},
+		{`{"Name":"lisbon","Budget":100}`, http.StatusBadRequest},
+		{`{"Name":`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		call(t, server, http.MethodPost, "/trips", c.body, c.status)
+	}
+
+	w = call(t, server, http.MethodPatch, "/trips/1", `{"Name":"goa 2019","Archived":true}`, http.StatusOK)
+	decodeBody(t, w, &info)
+	if info.Name != "goa 2019" || !info.Archived {
+		t.Errorf("got %+v", info)
+	}
+	call(t, server, http.MethodGet, "/trips/goa", "", http.StatusNotFound)
+	call(t, server, http.MethodPatch, "/trips/1", `{"Archived":false}`, http.StatusConflict)
+	call(t, server, http.MethodPost, "/trips/1/members", `{"Name":"jesse"}`, http.StatusConflict)
+
+	call(t, server, http.MethodDelete, "/trips/1", "", http.StatusNoContent)
+	call(t, server, http.MethodGet, "/trips/1", "", http.StatusNotFound)
+	trips := []database.TripInfo{}
+	decodeBody(t, call(t, server, http.MethodGet, "/trips", "", http.StatusOK), &trips)
+	if len(trips) != 0 {
+		t.Errorf("got %+v", trips)
+	}
+}
+
+func TestMembers(t *testing.T) {
+	defer inTempDir(t)()
+	server := testTrip(t)
+
+	member := database.Member{}
+	decodeBody(t, call(t, server, http.MethodGet, "/trips/goa/members/Heisenberg", "", http.StatusOK), &member)
+	if member.Name != "walt" {
+		t.Errorf("got %+v", member)
+	}
+	call(t, server, http.MethodPost, "/trips/goa/members", `{"Name":"Walt"}`, http.StatusConflict)
+	call(t, server, http.MethodPost, "/trips/goa/members", `{"Name":"saul, goodman"}`, http.StatusBadRequest)
+
+	decodeBody(t, call(t, server, http.MethodPut, "/trips/goa/members/gus", `{"Name":"gustavo","Email":"gus@example.com"}`, http.StatusOK), &member)
+	if member.Name != "gustavo" || member.Email != "gus@example.com" {
+		t.Errorf("got %+v", member)
+	}
+	call(t, server, http.MethodGet, "/trips/goa/members/gus", "", http.StatusNotFound)
+
+	call(t, server, http.MethodPost, "/trips/goa/transactions", `{"Name":"fuel","Amount":10,"Shares":[{"Member":"walt","Paid":10}]}`, http.StatusCreated)
+	call(t, server, http.MethodDelete, "/trips/goa/members/walt", "", http.StatusConflict)
+	call(t, server, http.MethodDelete, "/trips/goa/members/gustavo", "", http.StatusNoContent)
+
+	members := []database.Member{}
+	decodeBody(t, call(t, server, http.MethodGet, "/trips/goa/members", "", http.StatusOK), &members)
+	if len(members) != 2 {
+		t.Errorf("got %+v", members)
+	}
+}
+
+func TestAddTransaction(t *testing.T) {
+	defer inTempDir(t)()
+	server := testTrip(t)
+
+	cases := []struct {
+		body     string
+		status   int
+		split    string
+		amount   money.Money
+		shares   []money.Money
+		problems []string
+	}{
+		{
+			body:   `{"Name":"dinner","Amount":100,"Shares":[{"Member":"Heisenberg","Paid":100},{"Member":"jesse"},{"Member":"gus"}]}`,
+			status: http.StatusCreated,
+			split:  "equal",
+			amount: money.FromMinor(10000),
+			shares: []money.Money{money.FromMinor(3334), money.FromMinor(3333), money.FromMinor(3333)},
+		},
+		{
+			body:   `{"Name":"fuel","Shares":[{"Member":"walt","Amount":"30.50","Paid":40},{"Member":"jesse","Amount":"9.50"}]}`,
+			status: http.StatusCreated,
+			split:  "exact",
+			amount: money.FromMinor(4000),
+			shares: []money.Money{money.FromMinor(3050), money.FromMinor(950)},
+		},
+		{
+			body:     `{"Name":"rv","Amount":50,"Shares":[{"Member":"walt","Amount":30,"Paid":40},{"Member":"saul","Amount":30}]}`,
+			status:   http.StatusUnprocessableEntity,
+			problems: []string{"saul is not a member", "Total amount paid less than the bill amount", "Total share is more than the bill amount"},
+		},
+		{
+			body:     `{"Name":"rv","Amount":10,"Shares":[{"Member":"walt","Amount":-10,"Paid":10},{"Member":"jesse","Amount":20}]}`,
+			status:   http.StatusUnprocessableEntity,
+			problems: []string{"Share of walt -10.00 should not be negative"},
+		},
+		{body: `{"Name":"rv","Shares":[{"Member":"walt"}]}`, status: http.StatusBadRequest},
+		{body: `{"Name":"rv","Amount":10,"Split":"percent","Shares":[{"Member":"walt","Paid":10}]}`, status: http.StatusBadRequest},
+		{body: `{"Name":"","Amount":10,"Shares":[{"Member":"walt","Paid":10}]}`, status: http.StatusBadRequest},
+		{body: `{"Name":"rv","Amount":10}`, status: http.StatusBadRequest},
+		{body: `{"Name":"rv","Amount":"ten","Shares":[{"Member":"walt","Paid":10}]}`, status: http.StatusBadRequest},
+		{body: `{"Name":"rv","Amount":10,"Currency":"USD","Shares":[{"Member":"walt","Paid":10}]}`, status: http.StatusUnprocessableEntity},
+	}
+	for _, c := range cases {
+		w := call(t, server, http.MethodPost, "/trips/goa/transactions", c.body, c.status)
+		if c.status != http.StatusCreated {
+			body := errorBody{}
+			decodeBody(t, w, &body)
+			if c.problems != nil && strings.Join(body.Problems, "; ") != strings.Join(c.problems, "; ") {
+				t.Errorf("%s: got %+v", c.body, body)
+			}
+			continue
+		}
+
+		transaction := database.Transaction{}
+		decodeBody(t, w, &transaction)
+		if transaction.Split != c.split || transaction.Amount != c.amount || len(transaction.Shares) != len(c.shares) {
+			t.Errorf("%s: got %+v", c.body, transaction)
+			continue
+		}
+		for i, share := range transaction.Shares {
+			if share.Amount != c.shares[i] || share.Auto != (c.split == "equal") {
+				t.Errorf("%s: got share %+v, want %s", c.body, share, c.shares[i])
+			}
+		}
+		if transaction.Shares[0].Member != "walt" || transaction.Date.IsZero() {
+			t.Errorf("%s: got %+v", c.body, transaction)
+		}
+	}
+
+	transactions := []database.Transaction{}
+	decodeBody(t, call(t, server, http.MethodGet, "/trips/goa/transactions", "", http.StatusOK), &transactions)
+	if len(transactions) != 2 {
+		t.Errorf("got %d transactions, want 2", len(transactions))
+	}
+}
+
+func TestEqualSplitRemainder(t *testing.T) {
+	defer inTempDir(t)()
+	server := New("")
+	call(t, server, http.MethodPost, "/trips", `{"Name":"goa","Members":["walt","jesse","gus"],"Remainder":"payer"}`, http.StatusCreated)
+	call(t, server, http.MethodPost, "/trips", `{"Name":"lisbon","Members":["walt","jesse","gus"],"Remainder":"round-robin"}`, http.StatusCreated)
+
+	cent, share := money.FromMinor(3334), money.FromMinor(3333)
+	cases := []struct {
+		trip   string
+		shares []money.Money
+	}{
+		// the payer carries the cent left
+		{"goa", []money.Money{share, share, cent}},
+		// the cent moves on to the next member with each transaction
+		{"lisbon", []money.Money{cent, share, share}},
+		{"lisbon", []money.Money{share, cent, share}},
+		{"lisbon", []money.Money{share, share, cent}},
+	}
+	for i, c := range cases {
+		transaction := database.Transaction{}
+		w := call(t, server, http.MethodPost, "/trips/"+c.trip+"/transactions", `{"Name":"dinner","Amount":100,"Shares":[{"Member":"walt"},{"Member":"jesse"},{"Member":"gus","Paid":100}]}`, http.StatusCreated)
+		decodeBody(t, w, &transaction)
+		for j, share := range transaction.Shares {
+			if share.Amount != c.shares[j] {
+				t.Errorf("%d %s: got %s for %s, want %s", i, c.trip, share.Amount, share.Member, c.shares[j])
+			}
+		}
+	}
+
+	// editing the second transaction of lisbon keeps its place in the rotation
+	transaction := database.Transaction{}
+	decodeBody(t, call(t, server, http.MethodPut, "/trips/lisbon/transactions/2", `{"Name":"dinner","Amount":100,"Shares":[{"Member":"walt","Paid":100},{"Member":"jesse"},{"Member":"gus"}]}`, http.StatusOK), &transaction)
+	if transaction.Shares[1].Amount != cent {
+		t.Errorf("got %+v", transaction.Shares)
+	}
+}
+
+func TestAmountsInCurrency(t *testing.T) {
+	defer inTempDir(t)()
+	server := New("")
+
+	cases := []struct {
+		currency string
+		amount   string
+		minor    int64
+		shares   []json.Number
+		net      json.Number
+	}{
+		{"JPY", "1250", 1250, []json.Number{"625", "625"}, "625"},
+		{"KWD", "1.235", 1235, []json.Number{"0.618", "0.617"}, "0.617"},
+		{"EUR", "12.50", 1250, []json.Number{"6.25", "6.25"}, "6.25"},
+	}
+	for _, c := range cases {
+		trip := "/trips/" + c.currency
+		call(t, server, http.MethodPost, "/trips", `{"Name":"`+c.currency+`","Members":["walt","jesse"],"Currency":"`+c.currency+`"}`, http.StatusCreated)
+		w := call(t, server, http.MethodPost, trip+"/transactions", `{"Name":"ramen","Amount":`+c.amount+`,"Shares":[{"Member":"walt","Paid":`+c.amount+`},{"Member":"jesse"}]}`, http.StatusCreated)
+		body := transactionBody{}
+		decodeBody(t, w, &body)
+		if body.Amount != json.Number(c.amount) || body.Currency != c.currency || body.Shares[0].Amount != c.shares[0] || body.Shares[1].Amount != c.shares[1] {
+			t.Errorf("%s: got %+v", c.currency, body)
+		}
+
+		// the amount is stored in the minor units of the currency
+		stored, err := database.FindTransaction(c.currency, body.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Amount != money.FromMinor(c.minor) {
+			t.Errorf("%s: stored %d minor units, want %d", c.currency, stored.Amount.Minor(), c.minor)
+		}
+
+		// sending back what was read changes nothing
+		sent, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		updated := transactionBody{}
+		decodeBody(t, call(t, server, http.MethodPut, trip+"/transactions/1", string(sent), http.StatusOK), &updated)
+		if updated.Amount != body.Amount || updated.Shares[0].Paid != body.Shares[0].Paid {
+			t.Errorf("%s: got %+v, want %+v", c.currency, updated, body)
+		}
+
+		balances := balancesBody{}
+		decodeBody(t, call(t, server, http.MethodGet, trip+"/balances", "", http.StatusOK), &balances)
+		if balances.Currency != c.currency || balances.Balances[0].Net != c.net {
+			t.Errorf("%s: got %+v", c.currency, balances)
+		}
+		plan := planBody{}
+		decodeBody(t, call(t, server, http.MethodGet, trip+"/suggestions", "", http.StatusOK), &plan)
+		if len(plan.Suggestions) != 1 || plan.Suggestions[0].Amount != c.net {
+			t.Errorf("%s: got %+v", c.currency, plan)
+		}
+	}
+
+	call(t, server, http.MethodPost, "/trips/JPY/transactions", `{"Name":"tea","Amount":"12.5","Shares":[{"Member":"walt","Paid":"12.5"}]}`, http.StatusBadRequest)
+	call(t, server, http.MethodPost, "/trips/JPY/settlements", `{"From":"jesse","To":"walt","Amount":625}`, http.StatusCreated)
+	settlement := settlementBody{}
+	decodeBody(t, call(t, server, http.MethodGet, "/trips/JPY/settlements/1", "", http.StatusOK), &settlement)
+	if settlement.Amount != "625" || settlement.Currency != "JPY" {
+		t.Errorf("got %+v", settlement)
+	}
+}
+
+func TestUpdateTransaction(t *testing.T) {
+	defer inTempDir(t)()
+	server := testTrip(t)
+	w := call(t, server, http.MethodPost, "/trips/goa/transactions", `{"Name":"dinner","Amount":90,"Date":"2019-03-01T20:00:00Z","Shares":[{"Member":"walt","Paid":90},{"Member":"jesse"},{"Member":"gus"}]}`, http.StatusCreated)
+	if w.Header().Get("Location") != "/trips/goa/transactions/1" {
+		t.Errorf("got Location %s", w.Header().Get("Location"))
+	}
+
+	// the transaction read back is sent again with a new amount, the equal split is redone
+	transaction := database.Transaction{}
+	decodeBody(t, call(t, server, http.MethodGet, "/trips/goa/transactions/1", "", http.StatusOK), &transaction)
+	transaction.Amount = money.FromMinor(12000)
+	transaction.Shares[0].Paid = transaction.Amount
+	transaction.Date = time.Time{}
+	body, err := json.Marshal(transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeBody(t, call(t, server, http.MethodPut, "/trips/goa/transactions/1", string(body), http.StatusOK), &transaction)
+	if transaction.Shares[1].Amount != money.FromMinor(4000) || transaction.Date.Format("2006-01-02") != "2019-03-01" {
+		t.Errorf("got %+v", transaction)
+	}
+
+	call(t, server, http.MethodPut, "/trips/goa/transactions/1", `{"Name":"dinner","Amount":90,"Shares":[{"Member":"walt","Paid":80},{"Member":"jesse"}]}`, http.StatusUnprocessableEntity)
+	call(t, server, http.MethodPut, "/trips/goa/transactions/2", `{"Name":"dinner","Amount":90,"Shares":[{"Member":"walt","Paid":90}]}`, http.StatusNotFound)
+	call(t, server, http.MethodDelete, "/trips/goa/transactions/1", "", http.StatusNoContent)
+	call(t, server, http.MethodDelete, "/trips/goa/transactions/1", "", http.StatusNotFound)
+}
+
+func TestSettlements(t *testing.T) {
+	defer inTempDir(t)()
+	server := testTrip(t)
+
+	cases := []struct {
+		body   string
+		status int
+	}{
+		{`{"From":"jesse","To":"heisenberg","Amount":30,"Note":"cash"}`, http.StatusCreated},
+		{`{"From":"jesse","To":"saul","Amount":30}`, http.StatusUnprocessableEntity},
+		{`{"From":"jesse","To":"jesse","Amount":30}`, http.StatusBadRequest},
+		{`{"From":"jesse","To":"walt","Amount":0}`, http.StatusBadRequest},
+		{`{"To":"walt","Amount":10}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		call(t, server, http.MethodPost, "/trips/goa/settlements", c.body, c.status)
+	}
+
+	settlement := database.Settlement{}
+	decodeBody(t, call(t, server, http.MethodGet, "/trips/goa/settlements/1", "", http.StatusOK), &settlement)
+	if settlement.To != "walt" || settlement.Amount != money.FromMinor(3000) || settlement.Note != "cash" {
+		t.Errorf("got %+v", settlement)
+	}
+	decodeBody(t, call(t, server, http.MethodPut, "/trips/goa/settlements/1", `{"From":"gus","To":"walt","Amount":20}`, http.StatusOK), &settlement)
+	if settlement.Id != 1 || settlement.From != "gus" || settlement.Amount != money.FromMinor(2000) || settlement.Date.IsZero() {
+		t.Errorf("got %+v", settlement)
+	}
+	call(t, server, http.MethodDelete, "/trips/goa/settlements/1", "", http.StatusNoContent)
+	call(t, server, http.MethodGet, "/trips/goa/settlements/1", "", http.StatusNotFound)
+
+	settlements := []database.Settlement{}
+	decodeBody(t, call(t, server, http.MethodGet, "/trips/goa/settlements", "", http.StatusOK), &settlements)
+	if len(settlements) != 0 {
+		t.Errorf("got %+v", settlements)
+	}
+}
+
+func TestSuggestionsAndBalances(t *testing.T) {
+	defer inTempDir(t)()
+	server := testTrip(t)
+	call(t, server, http.MethodPost, "/trips/goa/transactions", `{"Name":"dinner","Amount":90,"Shares":[{"Member":"walt","Paid":90},{"Member":"jesse"},{"Member":"gus"}]}`, http.StatusCreated)
+	call(t, server, http.MethodPost, "/trips/goa/settlements", `{"From":"gus","To":"walt","Amount":30}`, http.StatusCreated)
+
+	cases := []struct {
+		query string
+		brief string
+	}{
+		{"", "You are settled up."},
+		{"?member=jesse", "Tienes que dar 30,00\u00a0€ a walt."},
+		{"?member=jesse&lang=en", "You have to give €30.00 to walt."},
+		{"?member=heisenberg&solver=min-transfers", "You get back €30.00 from jesse."},
+	}
+	for _, c := range cases {
+		planSuggestion := splitter.PlanSuggestion{}
+		decodeBody(t, call(t, server, http.MethodGet, "/trips/goa/suggestions"+c.query, "", http.StatusOK), &planSuggestion)
+		if len(planSuggestion.Suggestions) != 1 || planSuggestion.Currency != "EUR" || planSuggestion.Brief != c.brief {
+			t.Errorf("%s: got %+v", c.query, planSuggestion)
+			continue
+		}
+		suggestion := planSuggestion.Suggestions[0]
+		if suggestion.BMemberemail != "jesse" || suggestion.AMemberemail != "walt" || suggestion.Amount != money.FromMinor(3000) {
+			t.Errorf("%s: got %+v", c.query, suggestion)
+		}
+	}
+	call(t, server, http.MethodGet, "/trips/goa/suggestions?member=saul", "", http.StatusNotFound)
+	call(t, server, http.MethodGet, "/trips/goa/suggestions?solver=fastest", "", http.StatusBadRequest)
+	call(t, server, http.MethodGet, "/trips/goa/suggestions?currency=USD", "", http.StatusUnprocessableEntity)
+
+	body := balancesBody{}
+	decodeBody(t, call(t, server, http.MethodGet, "/trips/goa/balances", "", http.StatusOK), &body)
+	want := map[string]json.Number{"walt": "30.00", "jesse": "-30.00", "gus": "0.00"}
+	if body.Currency != "EUR" || len(body.Balances) != len(want) {
+		t.Fatalf("got %+v", body)
+	}
+	for _, balance := range body.Balances {
+		if balance.Net != want[balance.Memberemail] {
+			t.Errorf("%s: got net %s, want %s", balance.Memberemail, balance.Net, want[balance.Memberemail])
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+)
+
+// maxBodySize limits the request bodies, a transaction with a few hundred shares is well within it
+const maxBodySize = 1 << 20
+
+var (
+	errNotFound         = &Error{Status: http.StatusNotFound, Message: "Not found"}
+	errMethodNotAllowed = &Error{Status: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	errUnauthorized     = &Error{Status: http.StatusUnauthorized, Message: "Missing or wrong token"}
+)
+
+//Error is an error answered with its HTTP status
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func badRequest(message string) error {
+	return &Error{Status: http.StatusBadRequest, Message: message}
+}
+
+//Server answers the JSON REST API over the trips of the store.
+//The store is opened for every call, so the requests are served one at a time.
+type Server struct {
+	Token string // requests should carry it as Authorization: Bearer <token> when given
+	mu    sync.Mutex
+}
+
+//New returns the server of the API. Every request needs the token if it is not empty.
+func New(token string) *Server {
+	return &Server{Token: token}
+}
+
+// handler serves a request to the trip. Key is the id or the name of the member after the collection, empty for the collection.
+type handler func(w http.ResponseWriter, r *http.Request, trip string, key string) error
+
+// methods are the handlers of a path by the HTTP method
+type methods map[string]handler
+
+// collection is a resource within the trip along with each of its items
+type collection struct {
+	list methods
+	item methods
+}
+
+var collections = map[string]collection{
+	"members": {
+		list: methods{http.MethodGet: listMembers, http.MethodPost: addMember},
+		item: methods{http.MethodGet: getMember, http.MethodPut: editMember, http.MethodDelete: removeMember},
+	},
+	"transactions": {
+		list: methods{http.MethodGet: listTransactions, http.MethodPost: addTransaction},
+		item: methods{http.MethodGet: getTransaction, http.MethodPut: updateTransaction, http.MethodDelete: deleteTransaction},
+	},
+	"settlements": {
+		list: methods{http.MethodGet: listSettlements, http.MethodPost: addSettlement},
+		item: methods{http.MethodGet: getSettlement, http.MethodPut: updateSettlement, http.MethodDelete: deleteSettlement},
+	},
+	"suggestions": {list: methods{http.MethodGet: suggestions}},
+	"balances":    {list: methods{http.MethodGet: balances}},
+}
+
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if server.Token != "" && !validToken(r, server.Token) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="expensesplitter"`)
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if err := route(w, r); err != nil {
+		writeError(w, err)
+	}
+}
+
+// route finds the handler of the path:
+//
+//	/trips
+//	/trips/{id}
+//	/trips/{id}/{collection}
+//	/trips/{id}/{collection}/{key}
+//
+// The trip is given by its name or its id.
+func route(w http.ResponseWriter, r *http.Request) error {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if segments[0] != "trips" || len(segments) > 4 {
+		return errNotFound
+	}
+	if len(segments) == 1 {
+		return methods{http.MethodGet: listTrips, http.MethodPost: createTrip}.serve(w, r, "", "")
+	}
+
+	trip, err := database.ResolveTrip(segments[1])
+	if err != nil {
+		return err
+	}
+	if _, err := database.GetTrip(trip); err != nil {
+		return err
+	}
+	if len(segments) == 2 {
+		return methods{http.MethodGet: getTrip, http.MethodPatch: updateTrip, http.MethodDelete: deleteTrip}.serve(w, r, trip, "")
+	}
+
+	resource, ok := collections[segments[2]]
+	if !ok {
+		return errNotFound
+	}
+	if len(segments) == 3 {
+		return resource.list.serve(w, r, trip, "")
+	}
+	if resource.item == nil || segments[3] == "" {
+		return errNotFound
+	}
+	return resource.item.serve(w, r, trip, segments[3])
+}
+
+// serve calls the handler of the method, listing the allowed methods if there is none
+func (m methods) serve(w http.ResponseWriter, r *http.Request, trip string, key string) error {
+	if h, ok := m[r.Method]; ok {
+		return h(w, r, trip, key)
+	}
+	allowed := make([]string, 0, len(m))
+	for method := range m {
+		allowed = append(allowed, method)
+	}
+	sort.Strings(allowed)
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	return errMethodNotAllowed
+}
+
+func validToken(r *http.Request, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) == 1
+}
+
+// decode reads the JSON body of the request into the value. Unknown fields are refused to catch typos.
+func decode(w http.ResponseWriter, r *http.Request, value interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return badRequest("Invalid JSON body: " + err.Error())
+	}
+	return nil
+}
+
+// parseID reads the id of a transaction or a settlement from the path
+func parseID(key string) (int64, error) {
+	id, err := strconv.ParseInt(key, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errNotFound
+	}
+	return id, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(value)
+}
+
+// errorBody is the JSON answered for the errors. Problems lists each problem of the shares found by the validation.
+type errorBody struct {
+	Error    string
+	Problems []string `json:",omitempty"`
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	body := errorBody{Error: err.Error()}
+	var validation *splitter.ValidationResult
+	if errors.As(err, &validation) {
+		body.Error = "Invalid shares"
+		for _, problem := range validation.Problems {
+			body.Problems = append(body.Problems, problem.Error())
+		}
+	}
+	writeJSON(w, statusOf(err), body)
+}
+
+// statusOf maps the errors of the store and the splitter to the HTTP status
+func statusOf(err error) int {
+	var httpError *Error
+	if errors.As(err, &httpError) {
+		return httpError.Status
+	}
+	var validation *splitter.ValidationResult
+	if errors.As(err, &validation) {
+		return http.StatusUnprocessableEntity
+	}
+
+	switch {
+	case errors.Is(err, database.ErrTripNotFound),
+		errors.Is(err, database.ErrMemberNotFound),
+		errors.Is(err, database.ErrTransactionNotFound),
+		errors.Is(err, database.ErrSettlementNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrTripExists),
+		errors.Is(err, database.ErrMemberExists),
+		errors.Is(err, database.ErrMemberInUse),
+		errors.Is(err, database.ErrMemberOutsideDates),
+		errors.Is(err, database.ErrTripArchived),
+		errors.Is(err, database.ErrArchiveDefaultTrip):
+		return http.StatusConflict
+	case errors.Is(err, database.ErrInvalidTripName),
+		errors.Is(err, database.ErrInvalidMemberName),
+		errors.Is(err, database.ErrInvalidMemberDates),
+		errors.Is(err, database.ErrInvalidSettlement):
+		return http.StatusBadRequest
+	case errors.Is(err, splitter.ErrCurrencyNeeded),
+		errors.Is(err, money.ErrRateNotFound):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
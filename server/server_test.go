@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sankarvj/expensesplitter/database"
+	"github.com/sankarvj/expensesplitter/pkg/money"
+	"github.com/sankarvj/expensesplitter/pkg/splitter"
+)
+
+// inTempDir runs the test against an empty store, the store is kept in the working directory
+func inTempDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "expense")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		os.Chdir(wd)
+		os.RemoveAll(dir)
+	}
+}
+
+// call sends the request to the server and fails the test if the status is not the wanted one
+func call(t *testing.T, server http.Handler, method, path, body string, status int) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	if w.Code != status {
+		t.Fatalf("%s %s: got %d %s, want %d", method, path, w.Code, w.Body.String(), status)
+	}
+	return w
+}
+
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder, value interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(w.Body).Decode(value); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	defer inTempDir(t)()
+	server := New("")
+	call(t, server, http.MethodPost, "/trips", `{"Name":"goa"}`, http.StatusCreated)
+
+	cases := []struct {
+		method string
+		path   string
+		status int
+		allow  string
+	}{
+		{http.MethodGet, "/trips", http.StatusOK, ""},
+		{http.MethodGet, "/trips/", http.StatusOK, ""},
+		{http.MethodGet, "/trips/goa", http.StatusOK, ""},
+		{http.MethodGet, "/trips/1", http.StatusOK, ""},
+		{http.MethodGet, "/trips/goa/members", http.StatusOK, ""},
+		{http.MethodGet, "/trips/goa/transactions", http.StatusOK, ""},
+		{http.MethodGet, "/trips/goa/settlements", http.StatusOK, ""},
+		{http.MethodGet, "/trips/goa/suggestions", http.StatusOK, ""},
+		{http.MethodGet, "/trips/goa/balances", http.StatusOK, ""},
+		{http.MethodGet, "/", http.StatusNotFound, ""},
+		{http.MethodGet, "/members", http.StatusNotFound, ""},
+		{http.MethodGet, "/trips/lisbon", http.StatusNotFound, ""},
+		{http.MethodGet, "/trips/2/members", http.StatusNotFound, ""},
+		{http.MethodGet, "/trips/goa/expenses", http.StatusNotFound, ""},
+		{http.MethodGet, "/trips/goa/transactions/abc", http.StatusNotFound, ""},
+		{http.MethodGet, "/trips/goa/transactions/7", http.StatusNotFound, ""},
+		{http.MethodGet, "/trips/goa/settlements/7", http.StatusNotFound, ""},
+		{http.MethodGet, "/trips/goa/members/walt", http.StatusNotFound, ""},
+		{http.MethodGet, "/trips/goa/balances/walt", http.StatusNotFound, ""},
+		{http.MethodGet, "/trips/goa/members/walt/aliases", http.StatusNotFound, ""},
+		{http.MethodPut, "/trips", http.StatusMethodNotAllowed, "GET, POST"},
+		{http.MethodPut, "/trips/goa", http.StatusMethodNotAllowed, "DELETE, GET, PATCH"},
+		{http.MethodPatch, "/trips/goa/transactions/1", http.StatusMethodNotAllowed, "DELETE, GET, PUT"},
+		{http.MethodPost, "/trips/goa/suggestions", http.StatusMethodNotAllowed, "GET"},
+	}
+	for _, c := range cases {
+		w := call(t, server, c.method, c.path, "", c.status)
+		if w.Header().Get("Allow") != c.allow {
+			t.Errorf("%s %s: got Allow %q, want %q", c.method, c.path, w.Header().Get("Allow"), c.allow)
+		}
+		if w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+			t.Errorf("%s %s: got Content-Type %q", c.method, c.path, w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestToken(t *testing.T) {
+	defer inTempDir(t)()
+	server := httptest.NewServer(New("s3cret"))
+	defer server.Close()
+
+	cases := []struct {
+		authorization string
+		status        int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"s3cret", http.StatusUnauthorized},
+		{"Bearer s3cret", http.StatusOK},
+	}
+	for _, c := range cases {
+		request, err := http.NewRequest(http.MethodGet, server.URL+"/trips", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.authorization != "" {
+			request.Header.Set("Authorization", c.authorization)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != c.status {
+			t.Errorf("%q: got %d, want %d", c.authorization, response.StatusCode, c.status)
+		}
+		if c.status == http.StatusUnauthorized && response.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("%q: expected WWW-Authenticate", c.authorization)
+		}
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{database.ErrTripNotFound, http.StatusNotFound},
+		{database.ErrMemberNotFound, http.StatusNotFound},
+		{database.ErrTransactionNotFound, http.StatusNotFound},
+		{database.ErrSettlementNotFound, http.StatusNotFound},
+		{database.ErrTripExists, http.StatusConflict},
+		{database.ErrMemberExists, http.StatusConflict},
+		{database.ErrMemberInUse, http.StatusConflict},
+		{database.ErrTripArchived, http.StatusConflict},
+		{database.ErrArchiveDefaultTrip, http.StatusConflict},
+		{database.ErrInvalidTripName, http.StatusBadRequest},
+		{database.ErrInvalidSettlement, http.StatusBadRequest},
+		{badRequest("Invalid JSON body"), http.StatusBadRequest},
+		{&splitter.ValidationResult{Problems: []error{&splitter.UnknownMemberError{Email: "walt"}}}, http.StatusUnprocessableEntity},
+		{splitter.ErrCurrencyNeeded, http.StatusUnprocessableEntity},
+		{money.ErrRateNotFound, http.StatusUnprocessableEntity},
+		{os.ErrPermission, http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		if got := statusOf(c.err); got != c.status {
+			t.Errorf("%v: got %d, want %d", c.err, got, c.status)
+		}
+	}
+}